@@ -0,0 +1,199 @@
+package common
+
+import (
+	"fmt"
+	"log"
+)
+
+type DirectionName string
+
+const (
+	UP    DirectionName = "UP"
+	DOWN  DirectionName = "DOWN"
+	LEFT  DirectionName = "LEFT"
+	RIGHT DirectionName = "RIGHT"
+
+	UP_LEFT    DirectionName = "UP_LEFT"
+	UP_RIGHT   DirectionName = "UP_RIGHT"
+	DOWN_LEFT  DirectionName = "DOWN_LEFT"
+	DOWN_RIGHT DirectionName = "DOWN_RIGHT"
+)
+
+type Coord struct {
+	X, Y int
+}
+
+func (c Coord) Move(d DirectionDesc, n int) Coord {
+	return Coord{X: c.X + d.DeltaX*n, Y: c.Y + d.DeltaY*n}
+}
+
+type DirectionDesc struct {
+	Name           DirectionName
+	Char           rune
+	DeltaX, DeltaY int
+	*Directions
+}
+
+func (d DirectionDesc) Turns() [2]DirectionDesc {
+	return d.Directions.Turns(d)
+}
+
+func (d DirectionDesc) Opposite() DirectionDesc {
+	return d.Directions.Opposite(d)
+}
+
+func (d DirectionDesc) TurnLeft() DirectionDesc {
+	return d.Directions.TurnLeft(d)
+}
+
+func (d DirectionDesc) TurnRight() DirectionDesc {
+	return d.Directions.TurnRight(d)
+}
+
+func (d DirectionDesc) IsDiagonal() bool {
+	return d.DeltaX != 0 && d.DeltaY != 0
+}
+
+type Directions struct {
+	Up, Down, Left, Right                DirectionDesc
+	UpLeft, UpRight, DownLeft, DownRight DirectionDesc
+}
+
+func (d Directions) IsSlope(slope rune) bool {
+	switch slope {
+	case '^', '<', '>', 'v':
+		return true
+	}
+	return false
+}
+
+func (d Directions) SlopeToDirection(slope rune) DirectionDesc {
+	if !d.IsSlope(slope) {
+		log.Fatalf("Unreacheable")
+	}
+	direction, _ := d.FromRune(slope)
+	return direction
+}
+
+// FromRune parses any of the single character notations used by the puzzles:
+// U/D/L/R, N/S/E/W and ^/v/</>.
+func (d Directions) FromRune(r rune) (DirectionDesc, error) {
+	switch r {
+	case 'U', 'N', '^':
+		return d.Up, nil
+	case 'D', 'S', 'v':
+		return d.Down, nil
+	case 'L', 'W', '<':
+		return d.Left, nil
+	case 'R', 'E', '>':
+		return d.Right, nil
+	}
+	return DirectionDesc{}, fmt.Errorf("unknown direction %q", r)
+}
+
+func (d Directions) FromString(s string) (DirectionDesc, error) {
+	runes := []rune(s)
+	if len(runes) != 1 {
+		return DirectionDesc{}, fmt.Errorf("unknown direction %q", s)
+	}
+	return d.FromRune(runes[0])
+}
+
+// FromHexCode maps the direction digit of day 18 colours: 0 means R, 1 means
+// D, 2 means L, and 3 means U.
+func (d Directions) FromHexCode(code int) (DirectionDesc, error) {
+	switch code {
+	case 0:
+		return d.Right, nil
+	case 1:
+		return d.Down, nil
+	case 2:
+		return d.Left, nil
+	case 3:
+		return d.Up, nil
+	}
+	return DirectionDesc{}, fmt.Errorf("unknown direction code %d", code)
+}
+
+func (d Directions) Cardinal() []DirectionDesc {
+	return []DirectionDesc{d.Up, d.Right, d.Down, d.Left}
+}
+
+// All lists all 8 directions clockwise starting from Up.
+func (d Directions) All() []DirectionDesc {
+	return []DirectionDesc{d.Up, d.UpRight, d.Right, d.DownRight, d.Down, d.DownLeft, d.Left, d.UpLeft}
+}
+
+func NewDirections() Directions {
+	dirs := Directions{
+		Up: DirectionDesc{
+			UP, '^', 0, -1, nil,
+		},
+		Down: DirectionDesc{
+			DOWN, 'v', 0, 1, nil,
+		},
+		Left: DirectionDesc{
+			LEFT, '<', -1, 0, nil,
+		},
+		Right: DirectionDesc{
+			RIGHT, '>', 1, 0, nil,
+		},
+		UpLeft: DirectionDesc{
+			UP_LEFT, '↖', -1, -1, nil,
+		},
+		UpRight: DirectionDesc{
+			UP_RIGHT, '↗', 1, -1, nil,
+		},
+		DownLeft: DirectionDesc{
+			DOWN_LEFT, '↙', -1, 1, nil,
+		},
+		DownRight: DirectionDesc{
+			DOWN_RIGHT, '↘', 1, 1, nil,
+		},
+	}
+	dirs.Left.Directions = &dirs
+	dirs.Right.Directions = &dirs
+	dirs.Down.Directions = &dirs
+	dirs.Up.Directions = &dirs
+	dirs.UpLeft.Directions = &dirs
+	dirs.UpRight.Directions = &dirs
+	dirs.DownLeft.Directions = &dirs
+	dirs.DownRight.Directions = &dirs
+
+	return dirs
+}
+
+func (d Directions) Turns(desc DirectionDesc) [2]DirectionDesc {
+	switch desc.Char {
+	case '^', 'v':
+		return [2]DirectionDesc{d.Left, d.Right}
+	case '>', '<':
+		return [2]DirectionDesc{d.Up, d.Down}
+	}
+	log.Fatalf("Unreacheable")
+	return [2]DirectionDesc{d.Up, d.Down}
+}
+
+func (d Directions) byDelta(deltaX, deltaY int) DirectionDesc {
+	for _, desc := range d.All() {
+		if desc.DeltaX == deltaX && desc.DeltaY == deltaY {
+			return desc
+		}
+	}
+	log.Fatalf("Unreacheable")
+	return d.Up
+}
+
+func (d Directions) Opposite(desc DirectionDesc) DirectionDesc {
+	return d.byDelta(-desc.DeltaX, -desc.DeltaY)
+}
+
+// TurnLeft and TurnRight rotate by 90 degrees, keeping in mind that Y grows
+// downwards.
+func (d Directions) TurnLeft(desc DirectionDesc) DirectionDesc {
+	return d.byDelta(desc.DeltaY, -desc.DeltaX)
+}
+
+func (d Directions) TurnRight(desc DirectionDesc) DirectionDesc {
+	return d.byDelta(-desc.DeltaY, desc.DeltaX)
+}
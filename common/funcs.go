@@ -1,100 +1,13 @@
 package common
 
 import (
-	"bufio"
 	"fmt"
 	"github.com/samber/lo"
 	"log"
-	"os"
 	"strconv"
 	"strings"
 )
 
-type DirectionName string
-
-const (
-	UP    DirectionName = "UP"
-	DOWN  DirectionName = "DOWN"
-	LEFT  DirectionName = "LEFT"
-	RIGHT DirectionName = "RIGHT"
-)
-
-type Coord struct {
-	X, Y int
-}
-
-type DirectionDesc struct {
-	Name           DirectionName
-	Char           rune
-	DeltaX, DeltaY int
-	*Directions
-}
-
-func (d DirectionDesc) Turns() [2]DirectionDesc {
-	return d.Directions.Turns(d)
-}
-
-type Directions struct {
-	Up, Down, Left, Right DirectionDesc
-}
-
-func (d Directions) IsSlope(slope rune) bool {
-	switch slope {
-	case '^', '<', '>', 'v':
-		return true
-	}
-	return false
-}
-
-func (d Directions) SlopeToDirection(slope rune) DirectionDesc {
-	switch slope {
-	case '^':
-		return d.Up
-	case '<':
-		return d.Left
-	case 'v':
-		return d.Down
-	case '>':
-		return d.Right // todo d.Right
-	}
-	log.Fatalf("Unreacheable")
-	return d.Left
-}
-
-func NewDirections() Directions {
-	dirs := Directions{
-		Up: DirectionDesc{
-			UP, '^', 0, -1, nil,
-		},
-		Down: DirectionDesc{
-			DOWN, 'v', 0, 1, nil,
-		},
-		Left: DirectionDesc{
-			LEFT, '<', -1, 0, nil,
-		},
-		Right: DirectionDesc{
-			RIGHT, '>', 1, 0, nil,
-		},
-	}
-	dirs.Left.Directions = &dirs
-	dirs.Right.Directions = &dirs
-	dirs.Down.Directions = &dirs
-	dirs.Up.Directions = &dirs
-
-	return dirs
-}
-
-func (d Directions) Turns(desc DirectionDesc) [2]DirectionDesc {
-	switch desc.Char {
-	case '^', 'v':
-		return [2]DirectionDesc{d.Left, d.Right}
-	case '>', '<':
-		return [2]DirectionDesc{d.Up, d.Down}
-	}
-	log.Fatalf("Unreacheable")
-	return [2]DirectionDesc{d.Up, d.Down}
-}
-
 type Number interface {
 	int
 }
@@ -128,18 +41,15 @@ func NoIndex[T, R any](f func(T) R) func(T, int) R {
 }
 
 func FileToRows(path string) ([]string, error) {
-	readFile, err := os.Open(path)
-
+	lines, err := OpenLines(path)
 	if err != nil {
 		return []string{}, fmt.Errorf("Failed to read the file: %w", err)
 	}
-	fileScanner := bufio.NewScanner(readFile)
-	fileScanner.Split(bufio.ScanLines)
-	var lines []string
-	for fileScanner.Scan() {
-		lines = append(lines, fileScanner.Text())
+	rows, err := lines.Collect()
+	if err != nil {
+		return []string{}, fmt.Errorf("Failed to read the file: %w", err)
 	}
-	return lines, nil
+	return rows, nil
 }
 
 func StringOfNumbersToInts(s string) []int {
@@ -0,0 +1,209 @@
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// DefaultMaxTokenSize is large enough for the longest single-line inputs, the
+// bufio.Scanner default of 64KB is not.
+const DefaultMaxTokenSize = 16 * 1024 * 1024
+
+type readerConfig struct {
+	maxTokenSize int
+}
+
+type ReaderOption func(*readerConfig)
+
+func WithMaxTokenSize(size int) ReaderOption {
+	return func(c *readerConfig) {
+		c.maxTokenSize = size
+	}
+}
+
+func newScanner(r io.Reader, opts []ReaderOption) *bufio.Scanner {
+	config := readerConfig{maxTokenSize: DefaultMaxTokenSize}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, min(64*1024, config.maxTokenSize)), config.maxTokenSize)
+	return scanner
+}
+
+// Iterator streams values one by one:
+//
+//	for it.Next() {
+//		use(it.Value())
+//	}
+//	if err := it.Err(); err != nil { ... }
+//
+// Once Next returns false, the underlying file (if any) is closed.
+type Iterator[T any] struct {
+	advance  func() (T, bool)
+	err      func() error
+	closer   io.Closer
+	value    T
+	closed   bool
+	closeErr error
+}
+
+func (it *Iterator[T]) Next() bool {
+	if it.closed {
+		return false
+	}
+	value, ok := it.advance()
+	if !ok {
+		it.Close()
+		return false
+	}
+	it.value = value
+	return true
+}
+
+func (it *Iterator[T]) Value() T {
+	return it.value
+}
+
+func (it *Iterator[T]) Err() error {
+	if err := it.err(); err != nil {
+		return err
+	}
+	return it.closeErr
+}
+
+// Close releases the underlying file, it is safe to call it more than once.
+func (it *Iterator[T]) Close() error {
+	if it.closed {
+		return it.closeErr
+	}
+	it.closed = true
+	if it.closer != nil {
+		it.closeErr = it.closer.Close()
+	}
+	return it.closeErr
+}
+
+// Collect reads all the remaining values.
+func (it *Iterator[T]) Collect() ([]T, error) {
+	var values []T
+	for it.Next() {
+		values = append(values, it.Value())
+	}
+	return values, it.Err()
+}
+
+func scannerIterator(scanner *bufio.Scanner) *Iterator[string] {
+	return &Iterator[string]{
+		advance: func() (string, bool) {
+			if !scanner.Scan() {
+				return "", false
+			}
+			return scanner.Text(), true
+		},
+		err: scanner.Err,
+	}
+}
+
+func Lines(r io.Reader, opts ...ReaderOption) *Iterator[string] {
+	scanner := newScanner(r, opts)
+	scanner.Split(bufio.ScanLines)
+	return scannerIterator(scanner)
+}
+
+func splitOn(sep []byte) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.Index(data, sep); i >= 0 {
+			return i + len(sep), data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+var lineBreaks = strings.NewReplacer("\r", "", "\n", "")
+
+// Records splits the input on sep instead of newlines, like the
+// comma-separated sequence of day 15. Line breaks are dropped everywhere, as
+// if the input was a single line, then surrounding whitespace is trimmed from
+// every record, and empty records are skipped.
+func Records(r io.Reader, sep string, opts ...ReaderOption) *Iterator[string] {
+	if sep == "" {
+		// An empty separator would match without consuming any input.
+		return &Iterator[string]{
+			advance: func() (string, bool) { return "", false },
+			err:     func() error { return fmt.Errorf("empty record separator") },
+		}
+	}
+	scanner := newScanner(r, opts)
+	scanner.Split(splitOn([]byte(sep)))
+	return &Iterator[string]{
+		advance: func() (string, bool) {
+			for scanner.Scan() {
+				record := strings.TrimSpace(lineBreaks.Replace(scanner.Text()))
+				if record != "" {
+					return record, true
+				}
+			}
+			return "", false
+		},
+		err: scanner.Err,
+	}
+}
+
+// Blocks groups lines separated by blank lines. Several blank lines in a row
+// are treated as one separator.
+func Blocks(r io.Reader, opts ...ReaderOption) *Iterator[[]string] {
+	lines := Lines(r, opts...)
+	return &Iterator[[]string]{
+		advance: func() ([]string, bool) {
+			var block []string
+			for lines.Next() {
+				line := lines.Value()
+				if strings.TrimSpace(line) == "" {
+					if len(block) > 0 {
+						return block, true
+					}
+					continue
+				}
+				block = append(block, line)
+			}
+			return block, len(block) > 0
+		},
+		err: lines.Err,
+	}
+}
+
+func openWith[T any](path string, open func(r io.Reader) *Iterator[T]) (*Iterator[T], error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	it := open(file)
+	it.closer = file
+	return it, nil
+}
+
+func OpenLines(path string, opts ...ReaderOption) (*Iterator[string], error) {
+	return openWith(path, func(r io.Reader) *Iterator[string] {
+		return Lines(r, opts...)
+	})
+}
+
+func OpenRecords(path string, sep string, opts ...ReaderOption) (*Iterator[string], error) {
+	return openWith(path, func(r io.Reader) *Iterator[string] {
+		return Records(r, sep, opts...)
+	})
+}
+
+func OpenBlocks(path string, opts ...ReaderOption) (*Iterator[[]string], error) {
+	return openWith(path, func(r io.Reader) *Iterator[[]string] {
+		return Blocks(r, opts...)
+	})
+}
@@ -0,0 +1,27 @@
+package common
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRecords(t *testing.T) {
+	records, err := Records(strings.NewReader("rn=1,c\nm-,\r\nqp=3\n"), ",").Collect()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"rn=1", "cm-", "qp=3"}; !slices.Equal(records, want) {
+		t.Errorf("got %q, want %q", records, want)
+	}
+}
+
+func TestRecordsEmptySeparator(t *testing.T) {
+	it := Records(strings.NewReader("a,b"), "")
+	if it.Next() {
+		t.Fatalf("got record %q for an empty separator", it.Value())
+	}
+	if it.Err() == nil {
+		t.Errorf("expected an error for an empty separator")
+	}
+}
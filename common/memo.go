@@ -0,0 +1,165 @@
+package common
+
+import (
+	"container/list"
+	"fmt"
+)
+
+type MemoStats struct {
+	Hits, Misses, Evictions int
+}
+
+func (s MemoStats) Add(other MemoStats) MemoStats {
+	return MemoStats{
+		Hits:      s.Hits + other.Hits,
+		Misses:    s.Misses + other.Misses,
+		Evictions: s.Evictions + other.Evictions,
+	}
+}
+
+func (s MemoStats) String() string {
+	ratio := 0.0
+	if total := s.Hits + s.Misses; total > 0 {
+		ratio = float64(s.Hits) / float64(total)
+	}
+	return fmt.Sprintf("hits: %d, misses: %d (%.1f%% hit rate), evictions: %d", s.Hits, s.Misses, 100*ratio, s.Evictions)
+}
+
+type memoConfig struct {
+	maxSize int
+}
+
+type MemoOption func(*memoConfig)
+
+// WithMaxSize bounds the number of cached values, the least recently used
+// ones are evicted first. Zero means unbounded.
+func WithMaxSize(size int) MemoOption {
+	return func(c *memoConfig) {
+		c.maxSize = size
+	}
+}
+
+type memoEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// Memo is a cache of computed values. It is not safe for concurrent use.
+type Memo[K comparable, V any] struct {
+	maxSize int
+	// values holds the cache when the size is unbounded, entries and recent
+	// when it is bounded.
+	values  map[K]V
+	entries map[K]*list.Element
+	// recent has the most recently used entry in front.
+	recent *list.List
+	stats  MemoStats
+}
+
+func NewMemo[K comparable, V any](opts ...MemoOption) *Memo[K, V] {
+	var config memoConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	m := &Memo[K, V]{maxSize: config.maxSize}
+	m.init()
+	return m
+}
+
+func (m *Memo[K, V]) init() {
+	if m.maxSize > 0 {
+		m.entries = make(map[K]*list.Element)
+		m.recent = list.New()
+	} else {
+		m.values = make(map[K]V)
+	}
+}
+
+func (m *Memo[K, V]) Get(key K) (V, bool) {
+	if m.maxSize <= 0 {
+		value, ok := m.values[key]
+		if ok {
+			m.stats.Hits++
+		} else {
+			m.stats.Misses++
+		}
+		return value, ok
+	}
+	element, ok := m.entries[key]
+	if !ok {
+		m.stats.Misses++
+		var zero V
+		return zero, false
+	}
+	m.stats.Hits++
+	m.recent.MoveToFront(element)
+	return element.Value.(*memoEntry[K, V]).value, true
+}
+
+func (m *Memo[K, V]) Put(key K, value V) {
+	if m.maxSize <= 0 {
+		m.values[key] = value
+		return
+	}
+	if element, ok := m.entries[key]; ok {
+		element.Value.(*memoEntry[K, V]).value = value
+		m.recent.MoveToFront(element)
+		return
+	}
+	m.entries[key] = m.recent.PushFront(&memoEntry[K, V]{key, value})
+	for m.recent.Len() > m.maxSize {
+		oldest := m.recent.Back()
+		m.recent.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoEntry[K, V]).key)
+		m.stats.Evictions++
+	}
+}
+
+// GetOrCompute returns the cached value for key, or computes and caches it.
+// compute may itself use the memo, as recursive functions do.
+func (m *Memo[K, V]) GetOrCompute(key K, compute func() V) V {
+	if value, ok := m.Get(key); ok {
+		return value
+	}
+	value := compute()
+	m.Put(key, value)
+	return value
+}
+
+func (m *Memo[K, V]) Len() int {
+	if m.maxSize <= 0 {
+		return len(m.values)
+	}
+	return len(m.entries)
+}
+
+func (m *Memo[K, V]) Stats() MemoStats {
+	return m.stats
+}
+
+// Reset drops all the cached values and statistics.
+func (m *Memo[K, V]) Reset() {
+	m.init()
+	m.stats = MemoStats{}
+}
+
+// Memoize turns a recursive function into a memoized one. Instead of calling
+// itself, fn calls the recurse argument, so that the recursive calls go
+// through the cache too:
+//
+//	fib, _ := Memoize(func(fib func(int) int, n int) int {
+//		if n < 2 {
+//			return n
+//		}
+//		return fib(n-1) + fib(n-2)
+//	})
+func Memoize[K comparable, V any](fn func(recurse func(K) V, key K) V, opts ...MemoOption) (func(K) V, *Memo[K, V]) {
+	memo := NewMemo[K, V](opts...)
+	var memoized func(K) V
+	memoized = func(key K) V {
+		return memo.GetOrCompute(key, func() V {
+			return fn(memoized, key)
+		})
+	}
+	return memoized, memo
+}
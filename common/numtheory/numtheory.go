@@ -0,0 +1,267 @@
+package numtheory
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+var (
+	ErrOverflow   = errors.New("integer overflow")
+	ErrNoSolution = errors.New("no solution")
+)
+
+func AddChecked(a, b int64) (int64, error) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, fmt.Errorf("%d + %d: %w", a, b, ErrOverflow)
+	}
+	return sum, nil
+}
+
+func SubChecked(a, b int64) (int64, error) {
+	if b == math.MinInt64 {
+		return 0, fmt.Errorf("%d - %d: %w", a, b, ErrOverflow)
+	}
+	return AddChecked(a, -b)
+}
+
+func MulChecked(a, b int64) (int64, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, fmt.Errorf("%d * %d: %w", a, b, ErrOverflow)
+	}
+	return product, nil
+}
+
+// abs fails for math.MinInt64, which has no positive counterpart.
+func abs(a int64) (int64, error) {
+	if a == math.MinInt64 {
+		return 0, fmt.Errorf("|%d|: %w", a, ErrOverflow)
+	}
+	if a < 0 {
+		return -a, nil
+	}
+	return a, nil
+}
+
+// Gcd is always non-negative, Gcd(0, 0) is 0.
+func Gcd(a, b int64) (int64, error) {
+	a, err := abs(a)
+	if err != nil {
+		return 0, err
+	}
+	if b, err = abs(b); err != nil {
+		return 0, err
+	}
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a, nil
+}
+
+func GcdAll(values []int64) (int64, error) {
+	var g int64
+	for _, v := range values {
+		var err error
+		if g, err = Gcd(g, v); err != nil {
+			return 0, err
+		}
+	}
+	return g, nil
+}
+
+func Lcm(a, b int64) (int64, error) {
+	g, err := Gcd(a, b)
+	if err != nil {
+		return 0, err
+	}
+	if g == 0 {
+		return 0, nil
+	}
+	// Gcd already checked that neither value is math.MinInt64.
+	a, _ = abs(a)
+	b, _ = abs(b)
+	return MulChecked(a/g, b)
+}
+
+// LcmAll returns 1 for an empty slice.
+func LcmAll(values []int64) (int64, error) {
+	lcm := int64(1)
+	for _, v := range values {
+		var err error
+		lcm, err = Lcm(lcm, v)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return lcm, nil
+}
+
+func LcmAllBig(values []int64) *big.Int {
+	lcm := big.NewInt(1)
+	for _, v := range values {
+		value := new(big.Int).Abs(big.NewInt(v))
+		if value.Sign() == 0 {
+			return value
+		}
+		g := new(big.Int).GCD(nil, nil, lcm, value)
+		lcm.Mul(lcm.Div(lcm, g), value)
+	}
+	return lcm
+}
+
+// ExtendedGcd returns g = gcd(a, b) together with x and y such that
+// a*x + b*y = g.
+func ExtendedGcd(a, b int64) (g, x, y int64) {
+	oldR, r := a, b
+	oldX, x := int64(1), int64(0)
+	oldY, y := int64(0), int64(1)
+	for r != 0 {
+		q := oldR / r
+		oldR, r = r, oldR-q*r
+		oldX, x = x, oldX-q*x
+		oldY, y = y, oldY-q*y
+	}
+	if oldR < 0 {
+		return -oldR, -oldX, -oldY
+	}
+	return oldR, oldX, oldY
+}
+
+// Mod is the always non-negative remainder for a positive modulus.
+func Mod(a, m int64) int64 {
+	r := a % m
+	if r < 0 {
+		r += m
+	}
+	return r
+}
+
+func ModInverse(a, m int64) (int64, error) {
+	if m <= 0 {
+		return 0, fmt.Errorf("modulus %d must be positive", m)
+	}
+	g, x, _ := ExtendedGcd(Mod(a, m), m)
+	if g != 1 {
+		return 0, fmt.Errorf("inverse of %d mod %d: %w", a, m, ErrNoSolution)
+	}
+	return Mod(x, m), nil
+}
+
+// MergeCongruences solves x = a1 (mod m1), x = a2 (mod m2) for positive moduli
+// that are not necessarily coprime. The solution is returned modulo
+// lcm(m1, m2).
+func MergeCongruences(a1, m1, a2, m2 *big.Int) (*big.Int, *big.Int, error) {
+	p := new(big.Int)
+	g := new(big.Int).GCD(p, nil, m1, m2)
+	diff := new(big.Int).Sub(a2, a1)
+	if new(big.Int).Mod(diff, g).Sign() != 0 {
+		return nil, nil, fmt.Errorf("x = %s (mod %s) and x = %s (mod %s): %w", a1, m1, a2, m2, ErrNoSolution)
+	}
+	lcm := new(big.Int).Div(m1, g)
+	lcm.Mul(lcm, m2)
+	// m1*p + m2*q = g, so a1 + m1*p*(a2-a1)/g satisfies both congruences.
+	x := new(big.Int).Div(diff, g)
+	x.Mul(x, p)
+	x.Mul(x, m1)
+	x.Add(x, a1)
+	x.Mod(x, lcm)
+	return x, lcm, nil
+}
+
+func CrtBig(residues, moduli []*big.Int) (*big.Int, *big.Int, error) {
+	if len(residues) != len(moduli) {
+		return nil, nil, fmt.Errorf("got %d residues for %d moduli", len(residues), len(moduli))
+	}
+	x, modulus := big.NewInt(0), big.NewInt(1)
+	for i := range residues {
+		if moduli[i].Sign() <= 0 {
+			return nil, nil, fmt.Errorf("modulus %s must be positive", moduli[i])
+		}
+		var err error
+		x, modulus, err = MergeCongruences(x, modulus, residues[i], moduli[i])
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return x, modulus, nil
+}
+
+// Crt is CrtBig for inputs and results that fit into int64.
+func Crt(residues, moduli []int64) (int64, int64, error) {
+	bigResidues := make([]*big.Int, 0, len(residues))
+	for _, r := range residues {
+		bigResidues = append(bigResidues, big.NewInt(r))
+	}
+	bigModuli := make([]*big.Int, 0, len(moduli))
+	for _, m := range moduli {
+		bigModuli = append(bigModuli, big.NewInt(m))
+	}
+	x, modulus, err := CrtBig(bigResidues, bigModuli)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !modulus.IsInt64() {
+		return 0, 0, fmt.Errorf("modulus %s: %w", modulus, ErrOverflow)
+	}
+	return x.Int64(), modulus.Int64(), nil
+}
+
+// Isqrt returns the largest r such that r*r <= n, without going through
+// floating point.
+func Isqrt(n int64) (int64, error) {
+	if n < 0 {
+		return 0, fmt.Errorf("square root of negative number %d", n)
+	}
+	if n < 2 {
+		return n, nil
+	}
+	// The float estimate can be off by a few units for large n, so it is only
+	// used as a starting point and then corrected in integers.
+	r := int64(math.Sqrt(float64(n))) + 1
+	for r > n/r {
+		r = (r + n/r) / 2
+	}
+	for (r + 1) <= n/(r+1) {
+		r++
+	}
+	return r, nil
+}
+
+// LagrangeInterpolate evaluates at x the unique polynomial of degree
+// len(xs)-1 that goes through all the (xs[i], ys[i]) points. The computation is
+// exact, and an error is returned if the value is not an integer or does not
+// fit into int64.
+func LagrangeInterpolate(xs, ys []int64, x int64) (int64, error) {
+	if len(xs) != len(ys) || len(xs) == 0 {
+		return 0, fmt.Errorf("got %d xs and %d ys", len(xs), len(ys))
+	}
+	result := new(big.Rat)
+	for i := range xs {
+		term := new(big.Rat).SetInt64(ys[i])
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			if xs[i] == xs[j] {
+				return 0, fmt.Errorf("duplicate x %d", xs[i])
+			}
+			numerator := new(big.Int).Sub(big.NewInt(x), big.NewInt(xs[j]))
+			denominator := new(big.Int).Sub(big.NewInt(xs[i]), big.NewInt(xs[j]))
+			term.Mul(term, new(big.Rat).SetFrac(numerator, denominator))
+		}
+		result.Add(result, term)
+	}
+	if !result.IsInt() {
+		return 0, fmt.Errorf("interpolated value %s is not an integer", result)
+	}
+	if !result.Num().IsInt64() {
+		return 0, fmt.Errorf("interpolated value %s: %w", result, ErrOverflow)
+	}
+	return result.Num().Int64(), nil
+}
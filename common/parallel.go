@@ -0,0 +1,75 @@
+package common
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// WorkersFlag registers the --workers flag shared by the days that solve
+// independent items in parallel. Call flag.Parse before reading it.
+func WorkersFlag() *int {
+	return flag.Int("workers", runtime.NumCPU(), "number of items solved in parallel")
+}
+
+// ParallelMap applies fn to every item using the given number of workers, zero
+// or less meaning one per CPU, and returns the results in the order of items.
+// The first error cancels the context passed to the remaining calls, and items
+// that have not been started yet are skipped. The error returned is the one of
+// the earliest failed item, or an *Interrupted if ctx was cancelled.
+func ParallelMap[T, R any](ctx context.Context, items []T, workers int, fn func(ctx context.Context, item T) (R, error)) ([]R, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, len(items))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]R, len(items))
+	errs := make([]error, len(items))
+	indices := make(chan int)
+	var done atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				if ctx.Err() != nil {
+					continue
+				}
+				results[i], errs[i] = fn(ctx, items[i])
+				if errs[i] != nil {
+					cancel()
+				} else {
+					done.Add(1)
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := range items {
+		select {
+		case indices <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indices)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Cancellation from the outside, as opposed to an error of fn.
+	if err := ctx.Err(); err != nil {
+		return nil, &Interrupted{Progress: fmt.Sprintf("%d of %d items", done.Load(), len(items)), Err: err}
+	}
+	return results, nil
+}
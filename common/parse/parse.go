@@ -0,0 +1,308 @@
+// Package parse scans puzzle lines with templates like
+//
+//	"Card {id}: {winning...} | {have...}"
+//
+// Text outside of braces must match literally, except that a space matches any
+// non-empty run of whitespace, or any run at all next to a list, so that the
+// list may be empty. A placeholder captures everything up to the next literal. "{name...}" captures a whitespace-separated list into a slice, and
+// "{name...,}" a list separated by the characters after the dots. Literal
+// braces are written as "{{" and "}}".
+package parse
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Error points at the column of the line where scanning failed.
+type Error struct {
+	Line   string
+	Column int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("column %d: %s\n\t%s\n\t%s^", e.Column+1, e.Msg, e.Line, strings.Repeat(" ", e.Column))
+}
+
+type placeholder struct {
+	name      string
+	isList    bool
+	separator string
+}
+
+type segment struct {
+	literal     string
+	placeholder *placeholder
+	pattern     string
+}
+
+type Template struct {
+	raw          string
+	segments     []segment
+	placeholders []placeholder
+	re           *regexp.Regexp
+}
+
+var templateCache sync.Map // string -> *Template
+
+func isList(segments []segment, i int) bool {
+	return i >= 0 && i < len(segments) && segments[i].placeholder != nil && segments[i].placeholder.isList
+}
+
+// literalPattern lets the whitespace at an end of the literal be empty when a
+// list is on that side.
+func literalPattern(literal string, afterList, beforeList bool) string {
+	parts := strings.Split(literal, " ")
+	var sb strings.Builder
+	for i, part := range parts {
+		if i > 0 {
+			if (i == 1 && afterList && parts[0] == "") || (i == len(parts)-1 && beforeList && part == "") {
+				sb.WriteString(`\s*`)
+			} else {
+				sb.WriteString(`\s+`)
+			}
+		}
+		sb.WriteString(regexp.QuoteMeta(part))
+	}
+	return sb.String()
+}
+
+func segmentsPattern(segments []segment) string {
+	var sb strings.Builder
+	sb.WriteString(`^\s*`)
+	for _, s := range segments {
+		sb.WriteString(s.pattern)
+	}
+	return sb.String()
+}
+
+func Compile(template string) (*Template, error) {
+	if cached, ok := templateCache.Load(template); ok {
+		return cached.(*Template), nil
+	}
+	t := &Template{raw: template}
+	var literal strings.Builder
+	flushLiteral := func() {
+		if literal.Len() > 0 {
+			t.segments = append(t.segments, segment{literal: literal.String()})
+			literal.Reset()
+		}
+	}
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		if (c == '{' || c == '}') && i+1 < len(template) && template[i+1] == c {
+			literal.WriteByte(c)
+			i++
+			continue
+		}
+		if c == '}' {
+			return nil, fmt.Errorf("template %q: unexpected '}' at %d, use '}}' for a literal brace", template, i)
+		}
+		if c != '{' {
+			literal.WriteByte(c)
+			continue
+		}
+		closing := strings.IndexByte(template[i:], '}')
+		if closing == -1 {
+			return nil, fmt.Errorf("template %q: unclosed placeholder at %d", template, i)
+		}
+		p := placeholder{name: template[i+1 : i+closing]}
+		if name, separator, found := strings.Cut(p.name, "..."); found {
+			p = placeholder{name: name, isList: true, separator: separator}
+		}
+		flushLiteral()
+		if len(t.segments) > 0 && t.segments[len(t.segments)-1].placeholder != nil {
+			return nil, fmt.Errorf("template %q: placeholders {%s} and {%s} need a literal between them",
+				template, t.segments[len(t.segments)-1].placeholder.name, p.name)
+		}
+		t.segments = append(t.segments, segment{placeholder: &p})
+		t.placeholders = append(t.placeholders, p)
+		i += closing
+	}
+	flushLiteral()
+	for i := range t.segments {
+		if t.segments[i].placeholder != nil {
+			t.segments[i].pattern = `(.*?)`
+		} else {
+			t.segments[i].pattern = literalPattern(t.segments[i].literal, isList(t.segments, i-1), isList(t.segments, i+1))
+		}
+	}
+
+	re, err := regexp.Compile(segmentsPattern(t.segments) + `\s*$`)
+	if err != nil {
+		return nil, fmt.Errorf("template %q: %w", template, err)
+	}
+	t.re = re
+	templateCache.Store(template, t)
+	return t, nil
+}
+
+func MustCompile(template string) *Template {
+	t, err := Compile(template)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// mismatch finds the longest prefix of the template that still matches the
+// line, and reports the position right after it. A trailing placeholder of
+// the prefix matches lazily, so the position is where its value starts.
+func (t *Template) mismatch(line string) *Error {
+	for k := len(t.segments) - 1; k >= 0; k-- {
+		re := regexp.MustCompile(segmentsPattern(t.segments[:k]))
+		loc := re.FindStringIndex(line)
+		if loc == nil {
+			continue
+		}
+		if t.segments[k].placeholder != nil {
+			return &Error{Line: line, Column: loc[1], Msg: "unexpected text"}
+		}
+		msg := fmt.Sprintf("expected %q", t.segments[k].literal)
+		if k > 0 && t.segments[k-1].placeholder != nil {
+			msg = fmt.Sprintf("expected %q after {%s}", t.segments[k].literal, t.segments[k-1].placeholder.name)
+		}
+		return &Error{Line: line, Column: loc[1], Msg: msg}
+	}
+	return &Error{Line: line, Column: 0, Msg: fmt.Sprintf("does not match %q", t.raw)}
+}
+
+// Sscan matches the line against the template and stores the captured values.
+// Targets are either pointers in the order of placeholders, like fmt.Sscanf, or
+// a single pointer to a struct whose exported fields are matched with
+// placeholder names by the `parse:"name"` tag or by case-insensitive name.
+func (t *Template) Sscan(line string, targets ...any) error {
+	match := t.re.FindStringSubmatchIndex(line)
+	if match == nil {
+		return t.mismatch(line)
+	}
+
+	values, err := t.resolveTargets(targets)
+	if err != nil {
+		return err
+	}
+	for i, p := range t.placeholders {
+		start, end := match[2*i+2], match[2*i+3]
+		raw := line[start:end]
+		if err := assign(values[i], raw, p); err != nil {
+			return &Error{Line: line, Column: start, Msg: fmt.Sprintf("{%s}: %v", p.name, err)}
+		}
+	}
+	return nil
+}
+
+func Sscan(line string, template string, targets ...any) error {
+	t, err := Compile(template)
+	if err != nil {
+		return err
+	}
+	return t.Sscan(line, targets...)
+}
+
+func (t *Template) resolveTargets(targets []any) ([]reflect.Value, error) {
+	if len(targets) == 1 {
+		v := reflect.ValueOf(targets[0])
+		if v.Kind() == reflect.Pointer && v.Elem().Kind() == reflect.Struct && len(t.placeholders) > 0 {
+			return t.structFields(v.Elem())
+		}
+	}
+	if len(targets) != len(t.placeholders) {
+		return nil, fmt.Errorf("template %q has %d placeholders, got %d targets", t.raw, len(t.placeholders), len(targets))
+	}
+	values := make([]reflect.Value, 0, len(targets))
+	for i, target := range targets {
+		v := reflect.ValueOf(target)
+		if v.Kind() != reflect.Pointer || v.IsNil() {
+			return nil, fmt.Errorf("target %d for {%s} is not a pointer", i, t.placeholders[i].name)
+		}
+		values = append(values, v.Elem())
+	}
+	return values, nil
+}
+
+func (t *Template) structFields(s reflect.Value) ([]reflect.Value, error) {
+	values := make([]reflect.Value, 0, len(t.placeholders))
+	for _, p := range t.placeholders {
+		field, ok := findField(s, p.name)
+		if !ok {
+			return nil, fmt.Errorf("no field for {%s} in %s", p.name, s.Type())
+		}
+		if !field.CanSet() {
+			return nil, fmt.Errorf("field for {%s} in %s is not exported", p.name, s.Type())
+		}
+		values = append(values, field)
+	}
+	return values, nil
+}
+
+func findField(s reflect.Value, name string) (reflect.Value, bool) {
+	typ := s.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		if tag, ok := typ.Field(i).Tag.Lookup("parse"); ok && tag == name {
+			return s.Field(i), true
+		}
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		if strings.EqualFold(typ.Field(i).Name, name) {
+			return s.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func assign(target reflect.Value, raw string, p placeholder) error {
+	raw = strings.TrimSpace(raw)
+	if !p.isList {
+		return assignScalar(target, raw)
+	}
+	if target.Kind() != reflect.Slice {
+		return fmt.Errorf("list placeholder needs a slice, got %s", target.Type())
+	}
+	var items []string
+	if p.separator == "" {
+		items = strings.Fields(raw)
+	} else if raw != "" {
+		items = strings.Split(raw, p.separator)
+	}
+	slice := reflect.MakeSlice(target.Type(), len(items), len(items))
+	for i, item := range items {
+		if err := assignScalar(slice.Index(i), strings.TrimSpace(item)); err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+	target.Set(slice)
+	return nil
+}
+
+func assignScalar(target reflect.Value, raw string) error {
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, target.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		target.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, target.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", raw)
+		}
+		target.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, target.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		target.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", target.Type())
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package parse
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSscanEmptyLists(t *testing.T) {
+	type card struct {
+		Index   int   `parse:"id"`
+		Winning []int `parse:"winning"`
+		Have    []int `parse:"have"`
+	}
+	for _, tc := range []struct {
+		line          string
+		winning, have []int
+	}{
+		{"Card 1: | 1 2", nil, []int{1, 2}},
+		{"Card 1: 3 4 |", []int{3, 4}, nil},
+		{"Card 1: 3 4 | 1 2", []int{3, 4}, []int{1, 2}},
+	} {
+		var c card
+		if err := Sscan(tc.line, "Card {id}: {winning...} | {have...}", &c); err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.line, err)
+			continue
+		}
+		if c.Index != 1 || !slices.Equal(c.Winning, tc.winning) || !slices.Equal(c.Have, tc.have) {
+			t.Errorf("%q: got %+v", tc.line, c)
+		}
+	}
+}
+
+func TestSscanSpaceBetweenScalars(t *testing.T) {
+	var count int
+	var color string
+	if err := Sscan("3blue", "{count} {color}", &count, &color); err == nil {
+		t.Errorf("got %d %q without a space between them", count, color)
+	}
+}
@@ -0,0 +1,52 @@
+package common
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// Interrupted is returned by solvers stopped through their context, Progress
+// tells how far they got.
+type Interrupted struct {
+	Progress string
+	Err      error
+}
+
+func (e *Interrupted) Error() string {
+	return fmt.Sprintf("interrupted after %s: %v", e.Progress, e.Err)
+}
+
+func (e *Interrupted) Unwrap() error {
+	return e.Err
+}
+
+// CheckInterrupted returns nil while ctx is alive, and an *Interrupted with the
+// formatted progress once it is done.
+func CheckInterrupted(ctx context.Context, format string, args ...any) error {
+	if err := ctx.Err(); err != nil {
+		return &Interrupted{Progress: fmt.Sprintf(format, args...), Err: err}
+	}
+	return nil
+}
+
+// TimeoutFlag registers the --timeout flag, the time budget of a day.
+func TimeoutFlag() *time.Duration {
+	return flag.Duration("timeout", 0, "stop solving after this long, 0 means no limit")
+}
+
+// SolverContext is cancelled on Ctrl-C, and after the timeout unless it is 0.
+func SolverContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	if timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+)
+
+type acNode struct {
+	next map[byte]int
+	fail int
+	// outputs are the indices of the words ending at this node, including the
+	// ones reachable through the failure links.
+	outputs []int
+}
+
+// AhoCorasick finds all the occurrences of a set of words, overlapping ones
+// included, in a single pass over the text.
+type AhoCorasick struct {
+	words []string
+	nodes []acNode
+}
+
+type WordMatch struct {
+	Offset int
+	Word   int
+}
+
+func NewAhoCorasick(words []string) *AhoCorasick {
+	ac := &AhoCorasick{words: words, nodes: []acNode{{next: make(map[byte]int)}}}
+	for i, word := range words {
+		node := 0
+		for j := 0; j < len(word); j++ {
+			child, ok := ac.nodes[node].next[word[j]]
+			if !ok {
+				child = len(ac.nodes)
+				ac.nodes = append(ac.nodes, acNode{next: make(map[byte]int)})
+				ac.nodes[node].next[word[j]] = child
+			}
+			node = child
+		}
+		ac.nodes[node].outputs = append(ac.nodes[node].outputs, i)
+	}
+
+	// Breadth first, so that the failure links of shallower nodes are ready.
+	queue := make([]int, 0, len(ac.nodes))
+	for _, child := range ac.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for c, child := range ac.nodes[node].next {
+			ac.nodes[child].fail = ac.step(ac.nodes[node].fail, c)
+			ac.nodes[child].outputs = append(ac.nodes[child].outputs, ac.nodes[ac.nodes[child].fail].outputs...)
+			queue = append(queue, child)
+		}
+	}
+	return ac
+}
+
+func (ac *AhoCorasick) step(node int, c byte) int {
+	for {
+		if child, ok := ac.nodes[node].next[c]; ok {
+			return child
+		}
+		if node == 0 {
+			return 0
+		}
+		node = ac.nodes[node].fail
+	}
+}
+
+// FindAll returns the matches ordered by offset, and shorter words first for
+// the same offset.
+func (ac *AhoCorasick) FindAll(text string) []WordMatch {
+	var matches []WordMatch
+	node := 0
+	for i := 0; i < len(text); i++ {
+		node = ac.step(node, text[i])
+		for _, word := range ac.nodes[node].outputs {
+			matches = append(matches, WordMatch{Offset: i + 1 - len(ac.words[word]), Word: word})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b WordMatch) int {
+		if a.Offset != b.Offset {
+			return cmp.Compare(a.Offset, b.Offset)
+		}
+		return cmp.Compare(len(ac.words[a.Word]), len(ac.words[b.Word]))
+	})
+	return matches
+}
@@ -1,48 +1,149 @@
 package main
 
 import (
-	"github.com/dlclark/regexp2"
-	"log"
-	"strconv"
+	"fmt"
+	"github.com/samber/lo"
+	"maps"
+	"slices"
+	"unicode/utf8"
 )
 
+// Vocabulary maps the tokens that spell a digit to its value.
+type Vocabulary map[string]int
+
+var (
+	Digits = Vocabulary{
+		"0": 0, "1": 1, "2": 2, "3": 3, "4": 4, "5": 5, "6": 6, "7": 7, "8": 8, "9": 9,
+	}
+	EnglishWords = Vocabulary{
+		"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9,
+	}
+	EnglishZero = Vocabulary{"zero": 0}
+)
+
+func (v Vocabulary) Merge(others ...Vocabulary) Vocabulary {
+	merged := maps.Clone(v)
+	for _, other := range others {
+		maps.Copy(merged, other)
+	}
+	return merged
+}
+
+// Reversed spells every token backwards, for text that is written right to
+// left.
+func (v Vocabulary) Reversed() Vocabulary {
+	reversed := make(Vocabulary, len(v))
+	for token, digit := range v {
+		runes := []rune(token)
+		slices.Reverse(runes)
+		reversed[string(runes)] = digit
+	}
+	return reversed
+}
+
+type DigitMatch struct {
+	// Offset is in bytes from the start of the line.
+	Offset int
+	Token  string
+	Digit  int
+}
+
 type DigitExtractor interface {
-	Extract(s string) []int
+	Extract(s string) ([]DigitMatch, error)
 }
 
-type TrivialExtractor struct {
-	re *regexp2.Regexp
+// WordExtractor finds all the tokens of a vocabulary in a line, including the
+// overlapping ones like "twone".
+type WordExtractor struct {
+	tokens     []string
+	digits     []int
+	ignoreCase bool
+	matcher    *AhoCorasick
 }
 
-func (t *TrivialExtractor) Extract(s string) []int {
-	var matches []int
-	m, _ := t.re.FindStringMatch(s)
-	for m != nil {
-		value, err := strconv.Atoi(m.String())
-		if err != nil {
-			log.Fatalf("Failed to parse int from %s: %w", m.String(), err)
+// NewWordExtractor fails on vocabularies that are empty or have tokens that
+// are empty or do not stand for a single digit. With ignoreCase, ASCII letters
+// match regardless of their case.
+func NewWordExtractor(vocabulary Vocabulary, ignoreCase bool) (*WordExtractor, error) {
+	if len(vocabulary) == 0 {
+		return nil, fmt.Errorf("empty vocabulary")
+	}
+	// Sorted, so that the extractor does not depend on the map order.
+	tokens := lo.Keys(vocabulary)
+	slices.Sort(tokens)
+	e := &WordExtractor{ignoreCase: ignoreCase}
+	seen := make(map[string]string)
+	for _, token := range tokens {
+		digit := vocabulary[token]
+		if token == "" {
+			return nil, fmt.Errorf("empty token for %d", digit)
+		}
+		if digit < 0 || digit > 9 {
+			return nil, fmt.Errorf("token %q stands for %d, which is not a digit", token, digit)
+		}
+		key := token
+		if ignoreCase {
+			key = asciiLower(token)
+			if previous, ok := seen[key]; ok && vocabulary[previous] != digit {
+				return nil, fmt.Errorf("tokens %q and %q only differ in case but stand for different digits", previous, token)
+			} else if ok {
+				continue
+			}
+			seen[key] = token
 		}
-		matches = append(matches, value)
-		m, _ = t.re.FindNextMatch(m)
+		e.tokens = append(e.tokens, token)
+		e.digits = append(e.digits, digit)
+	}
+	keys := e.tokens
+	if ignoreCase {
+		keys = make([]string, 0, len(e.tokens))
+		for _, token := range e.tokens {
+			keys = append(keys, asciiLower(token))
+		}
+	}
+	e.matcher = NewAhoCorasick(keys)
+	return e, nil
+}
+
+func MustWordExtractor(vocabulary Vocabulary, ignoreCase bool) *WordExtractor {
+	e, err := NewWordExtractor(vocabulary, ignoreCase)
+	if err != nil {
+		panic(err)
 	}
-	return matches
+	return e
 }
 
-type ComplexExtractor struct {
-	re         *regexp2.Regexp
-	strToDigit map[string]int
+// asciiLower keeps the byte offsets intact, unlike strings.ToLower, which
+// may change the length of non-ASCII characters.
+func asciiLower(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if 'A' <= c && c <= 'Z' {
+			b[i] = c + 'a' - 'A'
+		}
+	}
+	return string(b)
 }
 
-func (c *ComplexExtractor) Extract(s string) []int {
-	var matches []int
-	m, _ := c.re.FindStringMatch(s)
-	for m != nil {
-		value, ok := c.strToDigit[m.Groups()[1].String()]
-		if !ok {
-			log.Fatalf("Failed to get int from %s", m.String())
+// Extract fails on lines that are not valid UTF-8, the tokens it returns
+// would not be text.
+func (e *WordExtractor) Extract(s string) ([]DigitMatch, error) {
+	for i, r := range s {
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+				return nil, fmt.Errorf("invalid UTF-8 at byte %d", i)
+			}
 		}
-		matches = append(matches, value)
-		m, _ = c.re.FindNextMatch(m)
 	}
-	return matches
+	text := s
+	if e.ignoreCase {
+		text = asciiLower(s)
+	}
+	wordMatches := e.matcher.FindAll(text)
+	matches := make([]DigitMatch, 0, len(wordMatches))
+	for _, m := range wordMatches {
+		end := m.Offset + len(e.tokens[m.Word])
+		matches = append(matches, DigitMatch{Offset: m.Offset, Token: s[m.Offset:end], Digit: e.digits[m.Word]})
+	}
+	return matches, nil
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+func (m DigitMatch) Kind() string {
+	if strings.IndexFunc(m.Token, func(r rune) bool { return !unicode.IsDigit(r) }) == -1 {
+		return "digit"
+	}
+	return "word"
+}
+
+// LineCalibration keeps everything that went into the calibration value of a
+// line. Lines without digits have no First and Last, and are worth 0.
+type LineCalibration struct {
+	Line        int
+	Text        string
+	Matches     []DigitMatch
+	First, Last *DigitMatch
+	Value       int
+}
+
+func (c LineCalibration) HasDigits() bool {
+	return len(c.Matches) > 0
+}
+
+func CalibrateLines(puzzle []string, extractor DigitExtractor) ([]LineCalibration, error) {
+	calibrations := make([]LineCalibration, 0, len(puzzle))
+	for i, text := range puzzle {
+		matches, err := extractor.Extract(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		c := LineCalibration{Line: i + 1, Text: text, Matches: matches}
+		if len(matches) > 0 {
+			c.First, c.Last = &matches[0], &matches[len(matches)-1]
+			c.Value = c.First.Digit*10 + c.Last.Digit
+		}
+		calibrations = append(calibrations, c)
+	}
+	return calibrations, nil
+}
+
+func WriteExplanation(w io.Writer, calibrations []LineCalibration) error {
+	total, withoutDigits := 0, 0
+	for _, c := range calibrations {
+		if !c.HasDigits() {
+			withoutDigits++
+			if _, err := fmt.Fprintf(w, "line %d: %q has no digits, counted as 0\n", c.Line, c.Text); err != nil {
+				return err
+			}
+			continue
+		}
+		total += c.Value
+		if _, err := fmt.Fprintf(w, "line %d: %q = %d\n", c.Line, c.Text, c.Value); err != nil {
+			return err
+		}
+		for i, m := range c.Matches {
+			var chosen []string
+			if i == 0 {
+				chosen = append(chosen, "first")
+			}
+			if i == len(c.Matches)-1 {
+				chosen = append(chosen, "last")
+			}
+			note := ""
+			if len(chosen) > 0 {
+				note = fmt.Sprintf(" (%s)", strings.Join(chosen, ", "))
+			}
+			if _, err := fmt.Fprintf(w, "  @%d %s %q -> %d%s\n", m.Offset, m.Kind(), m.Token, m.Digit, note); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintf(w, "Total: %d, %d of %d lines without digits\n", total, withoutDigits, len(calibrations))
+	return err
+}
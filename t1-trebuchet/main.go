@@ -3,8 +3,10 @@ package main
 import (
 	"advent_of_code/common"
 	"bufio"
+	"flag"
 	"fmt"
-	"github.com/dlclark/regexp2"
+	"github.com/samber/lo"
+	"io"
 	"log"
 	"os"
 )
@@ -13,7 +15,7 @@ func ReadPuzzle(path string) []string {
 	readFile, err := os.Open(path)
 
 	if err != nil {
-		log.Fatalf("Failed to read the file: %w", err)
+		log.Fatalf("Failed to read the file: %v", err)
 	}
 	fileScanner := bufio.NewScanner(readFile)
 	fileScanner.Split(bufio.ScanLines)
@@ -24,68 +26,106 @@ func ReadPuzzle(path string) []string {
 	return lines
 }
 
-func PuzzleToNumbers(puzzle []string, extractor DigitExtractor) [][]int {
+func PuzzleToNumbers(puzzle []string, extractor DigitExtractor) ([][]int, error) {
 	var numbers = make([][]int, 0, len(puzzle))
 
-	for _, p := range puzzle {
-		numbers = append(numbers, extractor.Extract(p))
+	for i, p := range puzzle {
+		matches, err := extractor.Extract(p)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		numbers = append(numbers, lo.Map(matches, func(m DigitMatch, index int) int {
+			return m.Digit
+		}))
 	}
-	return numbers
+	return numbers, nil
 }
 
-func GetCalibrationNumbers(allNumbers [][]int) []int {
+func GetCalibrationNumbers(allNumbers [][]int) ([]int, error) {
 	calNumbers := make([]int, 0, len(allNumbers))
-	for _, numbersInRow := range allNumbers {
+	for i, numbersInRow := range allNumbers {
+		if len(numbersInRow) == 0 {
+			return nil, fmt.Errorf("line %d has no digits", i+1)
+		}
 		calNumbers = append(calNumbers, numbersInRow[0]*10+numbersInRow[len(numbersInRow)-1])
 	}
-	return calNumbers
+	return calNumbers, nil
 }
 
-func WriteItemsToFile[T any](path string, items []T) {
-	writeFile, err := os.Create(path)
-	if err != nil {
-		log.Fatalf("Failed to open file for writing: %w", err)
-	}
+func WriteItems[T any](w io.Writer, items []T) error {
 	for _, item := range items {
-		fmt.Fprintf(writeFile, "%v\n", item)
+		if _, err := fmt.Fprintf(w, "%v\n", item); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
+var (
+	explain = flag.Bool("explain", false, "show how the calibration value of every line was found")
+	output  = flag.String("output", "", "write the results to this file instead of stdout")
+)
+
 // Part 1
 
 //func main() {
 //	puzzle := ReadPuzzle("/Users/iv/Code/advent-of-code-2023/t1-trebuchet/1.txt")
-//	numbers := PuzzleToNumbers(puzzle, &TrivialExtractor{re: regexp2.MustCompile("\\d", regexp2.IgnoreCase)})
-//	calNumbers := GetCalibrationNumbers(numbers)
-//	WriteItemsToFile("/Users/iv/Code/advent-of-code-2023/t1-trebuchet/1-output.txt", []int{sum(calNumbers)})
+//	numbers, err := PuzzleToNumbers(puzzle, MustWordExtractor(Digits, false))
+//	if err != nil {
+//		log.Fatalf("Failed to extract digits: %v", err)
+//	}
+//	calNumbers, err := GetCalibrationNumbers(numbers)
+//	if err != nil {
+//		log.Fatalf("Failed to calibrate: %v", err)
+//	}
+//	WriteItems(os.Stdout, []int{common.Sum(calNumbers)})
 //}
 
 // Part 2
+func writeResults(w io.Writer, puzzle []string, extractor DigitExtractor) error {
+	if *explain {
+		calibrations, err := CalibrateLines(puzzle, extractor)
+		if err != nil {
+			return fmt.Errorf("failed to extract digits: %w", err)
+		}
+		return WriteExplanation(w, calibrations)
+	}
+
+	numbers, err := PuzzleToNumbers(puzzle, extractor)
+	if err != nil {
+		return fmt.Errorf("failed to extract digits: %w", err)
+	}
+	calNumbers, err := GetCalibrationNumbers(numbers)
+	if err != nil {
+		return fmt.Errorf("failed to calibrate: %w", err)
+	}
+	return WriteItems(w, []int{common.Sum(calNumbers)})
+}
+
 func main() {
+	flag.Parse()
 	puzzle := ReadPuzzle("/Users/iv/Code/advent-of-code-2023/t1-trebuchet/1.txt")
-	numbers := PuzzleToNumbers(puzzle, &ComplexExtractor{
-		re: regexp2.MustCompile("(?=(\\d|one|two|three|four|five|six|seven|eight|nine))", regexp2.IgnoreCase),
-		strToDigit: map[string]int{
-			"1":     1,
-			"2":     2,
-			"3":     3,
-			"4":     4,
-			"5":     5,
-			"6":     6,
-			"7":     7,
-			"8":     8,
-			"9":     9,
-			"one":   1,
-			"two":   2,
-			"three": 3,
-			"four":  4,
-			"five":  5,
-			"six":   6,
-			"seven": 7,
-			"eight": 8,
-			"nine":  9,
-		},
-	})
-	calNumbers := GetCalibrationNumbers(numbers)
-	WriteItemsToFile("/Users/iv/Code/advent-of-code-2023/t1-trebuchet/2-output.txt", []int{common.Sum(calNumbers)})
+	extractor, err := NewWordExtractor(Digits.Merge(EnglishWords), true)
+	if err != nil {
+		log.Fatalf("Failed to build the extractor: %v", err)
+	}
+	if *output == "" {
+		if err := writeResults(os.Stdout, puzzle, extractor); err != nil {
+			log.Fatalf("Failed to write the results: %v", err)
+		}
+		return
+	}
+
+	out, err := os.Create(*output)
+	if err != nil {
+		log.Fatalf("Failed to open the output: %v", err)
+	}
+	err = writeResults(out, puzzle, extractor)
+	// The results are only complete once the file is closed.
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Fatalf("Failed to write the results to %s: %v", *output, err)
+	}
 }
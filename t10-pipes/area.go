@@ -0,0 +1,164 @@
+package main
+
+import (
+	"advent_of_code/common"
+	"fmt"
+	"github.com/samber/lo"
+	"slices"
+)
+
+var directionsToTile = map[[2]common.DirectionName]rune{
+	{common.UP, common.DOWN}:    UD,
+	{common.LEFT, common.RIGHT}: LR,
+	{common.UP, common.RIGHT}:   UR,
+	{common.UP, common.LEFT}:    UL,
+	{common.DOWN, common.LEFT}:  DL,
+	{common.DOWN, common.RIGHT}: DR,
+}
+
+// closesLoop follows the pipes leaving the start towards from, and tells
+// whether they lead back to the start from the direction to.
+func closesLoop(tiles [][]rune, start common.Coord, from, to common.DirectionDesc) bool {
+	c, movedTo := start.Move(from, 1), from
+	for c != start {
+		if !common.IsValidCoord(c, tiles) {
+			return false
+		}
+		exits := tileToDirections(tiles[c.Y][c.X])
+		if !slices.Contains(exits, movedTo.Opposite()) {
+			return false
+		}
+		next := exits[0]
+		if next == movedTo.Opposite() {
+			next = exits[1]
+		}
+		c, movedTo = c.Move(next, 1), next
+	}
+	return movedTo == to.Opposite()
+}
+
+// InferStartTile finds the pipe hidden under S. The start is connected to
+// neighbours that have a pipe pointing back at it, and when there are more
+// than two of them, the pair closing the loop wins.
+func InferStartTile(tiles [][]rune, start common.Coord) (rune, error) {
+	connected := make([]common.DirectionDesc, 0, 4)
+	for _, d := range []common.DirectionDesc{directions.Up, directions.Down, directions.Left, directions.Right} {
+		neighbour := start.Move(d, 1)
+		if !common.IsValidCoord(neighbour, tiles) {
+			continue
+		}
+		if slices.Contains(tileToDirections(tiles[neighbour.Y][neighbour.X]), d.Opposite()) {
+			connected = append(connected, d)
+		}
+	}
+	if len(connected) < 2 {
+		return NOOP, fmt.Errorf("start at %v connects to %d pipes, expected at least 2", start, len(connected))
+	}
+	var candidates []rune
+	for i := 0; i < len(connected)-1; i++ {
+		for j := i + 1; j < len(connected); j++ {
+			tile, ok := directionsToTile[[2]common.DirectionName{connected[i].Name, connected[j].Name}]
+			if ok && closesLoop(tiles, start, connected[i], connected[j]) {
+				candidates = append(candidates, tile)
+			}
+		}
+	}
+	if len(candidates) != 1 {
+		return NOOP, fmt.Errorf("start at %v closes %d loops, expected 1", start, len(candidates))
+	}
+	return candidates[0], nil
+}
+
+// StartDirection picks the exit of the start tile that walks the loop
+// clockwise, findInnerTiles expects the inside of the loop on the right.
+func StartDirection(field Field) common.DirectionDesc {
+	exits := tileToDirections(field.tiles[field.startingCoord.Y][field.startingCoord.X])
+	if SignedDoubleArea(LoopPath(field.startingCoord, field, exits[0])) < 0 {
+		return exits[1]
+	}
+	return exits[0]
+}
+
+// LoopPath returns the loop tiles in walking order, starting from the start
+// tile. The start tile is not repeated at the end.
+func LoopPath(startingCoord common.Coord, field Field, startMoveTo common.DirectionDesc) []common.Coord {
+	path := make([]common.Coord, 0)
+	closed := false
+	scanCycle(startingCoord, field, startMoveTo, func(c common.Coord, movedTo *common.DirectionDesc) {
+		if movedTo != nil && c == startingCoord {
+			closed = true
+		}
+		if !closed {
+			path = append(path, c)
+		}
+	})
+	return path
+}
+
+// LoopVertices keeps only the corners of the loop, which is enough to describe
+// it as a polygon.
+func LoopVertices(field Field, path []common.Coord) []common.Coord {
+	return lo.Filter(path, func(c common.Coord, index int) bool {
+		tile := field.tiles[c.Y][c.X]
+		return tile != UD && tile != LR
+	})
+}
+
+// SignedDoubleArea is positive when the vertices go clockwise, with the y
+// axis pointing down.
+func SignedDoubleArea(vertices []common.Coord) int {
+	doubleArea := 0
+	for i, v := range vertices {
+		next := vertices[(i+1)%len(vertices)]
+		doubleArea += v.X*next.Y - next.X*v.Y
+	}
+	return doubleArea
+}
+
+func ShoelaceDoubleArea(vertices []common.Coord) int {
+	doubleArea := SignedDoubleArea(vertices)
+	if doubleArea < 0 {
+		return -doubleArea
+	}
+	return doubleArea
+}
+
+// PickInterior applies Pick's theorem A = i + b/2 - 1 to find the number of
+// interior points i, where b is the number of tiles on the loop.
+func PickInterior(vertices []common.Coord, boundaryCount int) int {
+	return (ShoelaceDoubleArea(vertices)-boundaryCount)/2 + 1
+}
+
+// ScanlineInterior walks every row left to right and flips the inside flag
+// each time it crosses a loop tile with a pipe going up.
+func ScanlineInterior(field Field, loop map[common.Coord]struct{}) int {
+	count := 0
+	for y, row := range field.tiles {
+		inside := false
+		for x, tile := range row {
+			if _, onLoop := loop[common.Coord{X: x, Y: y}]; onLoop {
+				if tile == UD || tile == UR || tile == UL {
+					inside = !inside
+				}
+				continue
+			}
+			if inside {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func EnclosedTileCount(field Field, startMoveTo common.DirectionDesc) (int, error) {
+	path := LoopPath(field.startingCoord, field, startMoveTo)
+	loop := lo.SliceToMap(path, func(c common.Coord) (common.Coord, struct{}) {
+		return c, struct{}{}
+	})
+	byScanline := ScanlineInterior(field, loop)
+	byPick := PickInterior(LoopVertices(field, path), len(path))
+	if byScanline != byPick {
+		return 0, fmt.Errorf("scanline found %d enclosed tiles, but shoelace and Pick found %d", byScanline, byPick)
+	}
+	return byPick, nil
+}
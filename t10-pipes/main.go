@@ -8,14 +8,9 @@ import (
 	"log"
 )
 
-type Direction string
+var directions = common.NewDirections()
 
 const (
-	UP    Direction = "UP"
-	DOWN  Direction = "DOWN"
-	LEFT  Direction = "LEFT"
-	RIGHT Direction = "RIGHT"
-
 	UD   = '|'
 	LR   = '-'
 	UR   = 'L'
@@ -25,149 +20,96 @@ const (
 	NOOP = '.'
 )
 
-type Coord struct {
-	x, y int
-}
-
-func (c Coord) To(d Direction) Coord {
-	switch d {
-	case UP:
-		return Coord{c.x, c.y - 1}
-	case DOWN:
-		return Coord{c.x, c.y + 1}
-	case LEFT:
-		return Coord{c.x - 1, c.y}
-	case RIGHT:
-		return Coord{c.x + 1, c.y}
-	}
-	log.Fatalf("")
-	return Coord{}
-}
-
-func (c Coord) Neighbours(lenX, lenY int) []Coord {
-	coords := make([]Coord, 0)
-	if 0 <= c.x-1 && c.x-1 < lenX {
-		coords = append(coords, Coord{c.x - 1, c.y})
-	}
-	if 0 <= c.x+1 && c.x+1 < lenX {
-		coords = append(coords, Coord{c.x + 1, c.y})
-	}
-	if 0 <= c.y-1 && c.y-1 < lenY {
-		coords = append(coords, Coord{c.x, c.y - 1})
-	}
-	if 0 <= c.y+1 && c.y+1 < lenY {
-		coords = append(coords, Coord{c.x, c.y + 1})
-	}
-	return coords
-}
-
-func tileToDirections(tile rune) []Direction {
+func tileToDirections(tile rune) []common.DirectionDesc {
 	switch tile {
 	case UD:
-		return []Direction{UP, DOWN}
+		return []common.DirectionDesc{directions.Up, directions.Down}
 	case LR:
-		return []Direction{LEFT, RIGHT}
+		return []common.DirectionDesc{directions.Left, directions.Right}
 	case UR:
-		return []Direction{UP, RIGHT}
+		return []common.DirectionDesc{directions.Up, directions.Right}
 	case UL:
-		return []Direction{UP, LEFT}
+		return []common.DirectionDesc{directions.Up, directions.Left}
 	case DL:
-		return []Direction{DOWN, LEFT}
+		return []common.DirectionDesc{directions.Down, directions.Left}
 	case DR:
-		return []Direction{DOWN, RIGHT}
+		return []common.DirectionDesc{directions.Down, directions.Right}
 	case NOOP:
 		return nil
 	}
 	return nil
 }
 
-func tileAndMoveDirectionToInnerCandidates(tile rune, d Direction) []Direction {
+func tileAndMoveDirectionToInnerCandidates(tile rune, d common.DirectionDesc) []common.DirectionDesc {
 	switch tile {
 	case UD:
-		if d == UP {
-			return []Direction{RIGHT}
-		} else if d == DOWN {
-			return []Direction{LEFT}
+		if d == directions.Up {
+			return []common.DirectionDesc{directions.Right}
+		} else if d == directions.Down {
+			return []common.DirectionDesc{directions.Left}
 		} else {
 			log.Fatalf("Failed switch at %v", UD)
 		}
 	case LR:
-		if d == RIGHT {
-			return []Direction{DOWN}
-		} else if d == LEFT {
-			return []Direction{UP}
+		if d == directions.Right {
+			return []common.DirectionDesc{directions.Down}
+		} else if d == directions.Left {
+			return []common.DirectionDesc{directions.Up}
 		} else {
 			log.Fatalf("Failed switch at %v", LR)
 		}
 	case DR:
-		if d == UP {
-			return []Direction{}
-		} else if d == LEFT {
-			return []Direction{UP, LEFT}
+		if d == directions.Up {
+			return []common.DirectionDesc{}
+		} else if d == directions.Left {
+			return []common.DirectionDesc{directions.Up, directions.Left}
 		} else {
 			log.Fatalf("Failed switch at %v", DR)
 		}
 	case DL:
-		if d == UP {
-			return []Direction{UP, RIGHT}
-		} else if d == RIGHT {
-			return []Direction{}
+		if d == directions.Up {
+			return []common.DirectionDesc{directions.Up, directions.Right}
+		} else if d == directions.Right {
+			return []common.DirectionDesc{}
 		} else {
 			log.Fatalf("Failed switch at %v", DL)
 		}
 	case UR:
-		if d == DOWN {
-			return []Direction{LEFT, DOWN}
-		} else if d == LEFT {
-			return []Direction{}
+		if d == directions.Down {
+			return []common.DirectionDesc{directions.Left, directions.Down}
+		} else if d == directions.Left {
+			return []common.DirectionDesc{}
 		} else {
 			log.Fatalf("Failed switch at %v", UR)
 		}
 	case UL:
-		if d == DOWN {
-			return []Direction{}
-		} else if d == RIGHT {
-			return []Direction{RIGHT, DOWN}
+		if d == directions.Down {
+			return []common.DirectionDesc{}
+		} else if d == directions.Right {
+			return []common.DirectionDesc{directions.Right, directions.Down}
 		} else {
 			log.Fatalf("Failed switch at %v", UL)
 		}
 	}
 	log.Fatalf("Broken switch")
-	return []Direction{}
+	return []common.DirectionDesc{}
 }
 
-func getOppositeDirection(d Direction) Direction {
-	var opposite Direction
-	switch d {
-	case UP:
-		opposite = DOWN
-	case DOWN:
-		opposite = UP
-	case LEFT:
-		opposite = RIGHT
-	case RIGHT:
-		opposite = LEFT
-	default:
-		log.Fatalf("Unexpected")
-	}
-	return opposite
-}
-
-func findStartingCoords(tiles [][]rune) Coord {
+func findStartingCoords(tiles [][]rune) common.Coord {
 	for y := 0; y < len(tiles); y++ {
 		for x := 0; x < len(tiles[0]); x++ {
 			if tiles[y][x] == 'S' {
-				return Coord{x, y}
+				return common.Coord{X: x, Y: y}
 			}
 		}
 	}
 	log.Fatalf("Failed to find")
-	return Coord{}
+	return common.Coord{}
 }
 
 type Field struct {
 	tiles         [][]rune
-	startingCoord Coord
+	startingCoord common.Coord
 }
 
 func (f Field) LenX() int {
@@ -177,8 +119,8 @@ func (f Field) LenY() int {
 	return len(f.tiles)
 }
 
-func (f Field) Move(from Coord, notTo *Direction, startMoveTo Direction) (Coord, *Direction, *Direction) {
-	directions := lo.Filter(tileToDirections(f.tiles[from.y][from.x]), func(item Direction, index int) bool {
+func (f Field) Move(from common.Coord, notTo *common.DirectionDesc, startMoveTo common.DirectionDesc) (common.Coord, *common.DirectionDesc, *common.DirectionDesc) {
+	directions := lo.Filter(tileToDirections(f.tiles[from.Y][from.X]), func(item common.DirectionDesc, index int) bool {
 		return notTo == nil || item != *notTo
 	})
 	if notTo == nil && len(directions) != 2 {
@@ -187,33 +129,22 @@ func (f Field) Move(from Coord, notTo *Direction, startMoveTo Direction) (Coord,
 	if notTo != nil && len(directions) != 1 {
 		log.Fatalf("Unexpected while not nil %d", len(directions))
 	}
-	var directionToMove Direction
+	var directionToMove common.DirectionDesc
 	if notTo == nil {
 		directionToMove = startMoveTo
 	} else {
 		directionToMove = directions[0]
 	}
-	switch directionToMove {
-	case UP:
-		from.y -= 1
-	case DOWN:
-		from.y += 1
-	case RIGHT:
-		from.x += 1
-	case LEFT:
-		from.x -= 1
-	default:
-		log.Fatalf("Unexpected direction %v", directionToMove)
-	}
-	opposite := getOppositeDirection(directionToMove)
+	from = from.Move(directionToMove, 1)
+	opposite := directionToMove.Opposite()
 	return from, &directionToMove, &opposite
 }
 
-func scanCycle(startingCoord Coord, field Field, startMoveTo Direction, callback func(c Coord, movedTo *Direction)) {
+func scanCycle(startingCoord common.Coord, field Field, startMoveTo common.DirectionDesc, callback func(c common.Coord, movedTo *common.DirectionDesc)) {
 	moved := false
 	slowerPointer := startingCoord
 	fasterPointer := startingCoord
-	var lastFastOppositeDirection, movedTo, lastSlowOppositeDirection *Direction
+	var lastFastOppositeDirection, movedTo, lastSlowOppositeDirection *common.DirectionDesc
 	slowerMoved := 0
 	callback(fasterPointer, nil)
 	for !moved || fasterPointer != startingCoord {
@@ -227,24 +158,24 @@ func scanCycle(startingCoord Coord, field Field, startMoveTo Direction, callback
 	}
 }
 
-func scanCycleTiles(startingCoord Coord, field Field, startMoveTo Direction) map[Coord]struct{} {
-	cycleTiles := make(map[Coord]struct{})
-	scanCycle(startingCoord, field, startMoveTo, func(c Coord, movedTo *Direction) {
+func scanCycleTiles(startingCoord common.Coord, field Field, startMoveTo common.DirectionDesc) map[common.Coord]struct{} {
+	cycleTiles := make(map[common.Coord]struct{})
+	scanCycle(startingCoord, field, startMoveTo, func(c common.Coord, movedTo *common.DirectionDesc) {
 		cycleTiles[c] = struct{}{}
 	})
 	return cycleTiles
 }
 
-func findInnerTiles(startingCoord Coord, field Field, cycleTileSet map[Coord]struct{}, startMoveTo Direction) map[Coord]struct{} {
-	innerTiles := make(map[Coord]struct{})
-	scanCycle(startingCoord, field, startMoveTo, func(c Coord, movedTo *Direction) {
+func findInnerTiles(startingCoord common.Coord, field Field, cycleTileSet map[common.Coord]struct{}, startMoveTo common.DirectionDesc) map[common.Coord]struct{} {
+	innerTiles := make(map[common.Coord]struct{})
+	scanCycle(startingCoord, field, startMoveTo, func(c common.Coord, movedTo *common.DirectionDesc) {
 		if movedTo == nil {
 			return
 		}
 		candidates := lo.Map(
-			tileAndMoveDirectionToInnerCandidates(field.tiles[c.y][c.x], *movedTo),
-			func(d Direction, index int) Coord {
-				return c.To(d)
+			tileAndMoveDirectionToInnerCandidates(field.tiles[c.Y][c.X], *movedTo),
+			func(d common.DirectionDesc, index int) common.Coord {
+				return c.Move(d, 1)
 			})
 
 		for _, candidate := range candidates {
@@ -257,8 +188,8 @@ func findInnerTiles(startingCoord Coord, field Field, cycleTileSet map[Coord]str
 	return innerTiles
 }
 
-func bfsInner(field Field, starting Coord, visited *map[Coord]struct{}, predicate func(c Coord) bool, visitor func(c Coord)) {
-	q := queue.New[Coord]()
+func bfsInner(field Field, starting common.Coord, visited *map[common.Coord]struct{}, predicate func(c common.Coord) bool, visitor func(c common.Coord)) {
+	q := queue.New[common.Coord]()
 	q.Enqueue(starting)
 	for !q.Empty() {
 		current := q.Dequeue()
@@ -267,15 +198,15 @@ func bfsInner(field Field, starting Coord, visited *map[Coord]struct{}, predicat
 		}
 		visitor(current)
 		(*visited)[current] = struct{}{}
-		neighbours := lo.Filter(current.Neighbours(field.LenX(), field.LenY()), common.NoIndex(predicate))
+		neighbours := lo.Filter(common.AdjacentCoords(current, field.tiles), common.NoIndex(predicate))
 		for _, n := range neighbours {
 			q.Enqueue(n)
 		}
 	}
 }
 
-func bfs(field Field, startingCoords []Coord, predicate func(c Coord) bool, visitor func(c Coord)) {
-	visited := make(map[Coord]struct{})
+func bfs(field Field, startingCoords []common.Coord, predicate func(c common.Coord) bool, visitor func(c common.Coord)) {
+	visited := make(map[common.Coord]struct{})
 	for _, startingCoord := range startingCoords {
 		if _, exists := visited[startingCoord]; exists {
 			continue
@@ -286,34 +217,38 @@ func bfs(field Field, startingCoords []Coord, predicate func(c Coord) bool, visi
 
 func main() {
 	//rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t10-pipes/test.txt")
-	//startIsRune := DR
 	//rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t10-pipes/2test1.txt")
-	//startIsRune := DR
-	//moveTo := RIGHT
 	//rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t10-pipes/2test2.txt")
-	//startIsRune := DR
-	//moveTo := DOWN
 	rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t10-pipes/1.txt")
-	startIsRune := UD
-	moveTo := DOWN
 	if err != nil {
-		log.Fatalf("Failed to open file: %w", err)
+		log.Fatalf("Failed to open file: %v", err)
 	}
 	tiles := lo.Map(rows, common.NoIndex(func(s string) []rune {
 		return []rune(s)
 	}))
 	startingCoord := findStartingCoords(tiles)
-	tiles[startingCoord.y][startingCoord.x] = startIsRune
+	startIsRune, err := InferStartTile(tiles, startingCoord)
+	if err != nil {
+		log.Fatalf("Failed to infer the start tile: %v", err)
+	}
+	tiles[startingCoord.Y][startingCoord.X] = startIsRune
 	field := Field{tiles: tiles, startingCoord: startingCoord}
+	moveTo := StartDirection(field)
 
 	tileSet := scanCycleTiles(startingCoord, field, moveTo)
 	innerTileSubset := findInnerTiles(startingCoord, field, tileSet, moveTo)
-	innerTileSet := make(map[Coord]struct{})
-	bfs(field, lo.Keys(innerTileSubset), func(c Coord) bool {
+	innerTileSet := make(map[common.Coord]struct{})
+	bfs(field, lo.Keys(innerTileSubset), func(c common.Coord) bool {
 		_, exists := tileSet[c]
 		return !exists
-	}, func(c Coord) {
+	}, func(c common.Coord) {
 		innerTileSet[c] = struct{}{}
 	})
 	fmt.Printf("%d\n", len(innerTileSet))
+
+	enclosed, err := EnclosedTileCount(field, moveTo)
+	if err != nil {
+		log.Fatalf("Failed to count enclosed tiles: %v", err)
+	}
+	fmt.Printf("Scanline and Pick: %d\n", enclosed)
 }
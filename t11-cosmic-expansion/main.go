@@ -2,113 +2,62 @@ package main
 
 import (
 	"advent_of_code/common"
+	"flag"
 	"fmt"
-	"github.com/samber/lo"
 	"log"
 )
 
 type Tile rune
 
 const (
-	EMPTY_SPACE      Tile = '*'
+	EMPTY_SPACE      Tile = '.'
 	GALAXY           Tile = '#'
 	EXPANSION_FACTOR      = 1000000
 )
 
-type Coord struct {
-	x, y int
-}
-
-func LenX(tiles [][]Tile) int {
-	return len(tiles[0])
-}
-
-func LenY(tiles [][]Tile) int {
-	return len(tiles)
-}
-
-func XAllSpace(tiles [][]Tile, x int) bool {
-	for y := 0; y < LenY(tiles); y++ {
-		if tiles[y][x] == GALAXY {
-			return false
-		}
-	}
-	return true
-}
-
-func YAllSpace(tiles [][]Tile, y int) bool {
-	for x := 0; x < LenX(tiles); x++ {
-		if tiles[y][x] == GALAXY {
-			return false
-		}
-	}
-	return true
-}
-
-func GalaxyCoords(tiles [][]Tile) []Coord {
-	galaxies := make([]Coord, 0)
-	for y := 0; y < LenY(tiles); y++ {
-		for x := 0; x < LenX(tiles); x++ {
-			if tiles[y][x] == GALAXY {
-				galaxies = append(galaxies, Coord{x, y})
-			}
-		}
-	}
-	return galaxies
-}
-
-func absInt(x int) int {
-	if x < 0 {
-		x = -x
-	}
-	return x
-}
-
-func manhattanDistance(first, second Coord, xCumDistances, yCumDistances []int) int {
-	return absInt(xCumDistances[first.x]-xCumDistances[second.x]) + absInt(yCumDistances[first.y]-yCumDistances[second.y])
-}
+var (
+	expansion    = flag.Int("expansion", EXPANSION_FACTOR, "how many rows or columns every empty one becomes")
+	bucketWidth  = flag.Int("buckets", 0, "print the distribution of the distances in buckets of this width")
+	printExtreme = flag.Bool("extremes", false, "print the nearest and the farthest pairs of galaxies")
+)
 
 func main() {
+	flag.Parse()
+	if *expansion < 1 {
+		log.Fatalf("Expansion %d is less than 1", *expansion)
+	}
 	rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t11-cosmic-expansion/1.txt")
 	if err != nil {
-		log.Fatalf("Failed to open file: %w", err)
+		log.Fatalf("Failed to open file: %v", err)
 	}
+	universe, err := NewUniverse(rows)
+	if err != nil {
+		log.Fatalf("Failed to parse the universe: %v", err)
+	}
+	fmt.Printf("Distance: %d\n", universe.Distances(*expansion))
 
-	tiles := lo.Map(rows, common.NoIndex(func(row string) []Tile {
-		return []Tile(row)
-	}))
-	galaxyCoords := GalaxyCoords(tiles)
-	xCumDistances := lo.Reduce(lo.Range(LenX(tiles)), func(agg []int, x int, index int) []int {
-		var prev int
-		if len(agg) > 0 {
-			prev = agg[len(agg)-1]
-		}
-		if XAllSpace(tiles, x) {
-			agg = append(agg, prev+EXPANSION_FACTOR)
+	if *printExtreme {
+		nearest, ok := universe.NearestPair(*expansion)
+		if !ok {
+			fmt.Printf("No pairs among %d galaxies\n", len(universe.Galaxies))
 		} else {
-			agg = append(agg, prev+1)
-		}
-		return agg
-	}, make([]int, 0, LenX(tiles)))
-	yCumDistances := lo.Reduce(lo.Range(LenY(tiles)), func(agg []int, y int, index int) []int {
-		var prev int
-		if len(agg) > 0 {
-			prev = agg[len(agg)-1]
+			farthest, _ := universe.FarthestPair(*expansion)
+			for _, p := range []struct {
+				name string
+				Pair
+			}{{"Nearest", nearest}, {"Farthest", farthest}} {
+				fmt.Printf("%s: galaxies %d %v and %d %v, %d apart\n",
+					p.name, p.First.Number, p.First.Coord, p.Second.Number, p.Second.Coord, p.Distance)
+			}
 		}
-		if YAllSpace(tiles, y) {
-			agg = append(agg, prev+EXPANSION_FACTOR)
-		} else {
-			agg = append(agg, prev+1)
+	}
+	if *bucketWidth > 0 {
+		buckets, err := universe.DistanceDistribution(*expansion, *bucketWidth)
+		if err != nil {
+			log.Fatalf("Failed to compute the distribution: %v", err)
 		}
-		return agg
-	}, make([]int, 0, LenY(tiles)))
-
-	total := 0
-	for i := 0; i < len(galaxyCoords)-1; i++ {
-		for j := i + 1; j < len(galaxyCoords); j++ {
-			distance := manhattanDistance(galaxyCoords[i], galaxyCoords[j], xCumDistances, yCumDistances)
-			total += distance
+		for _, b := range buckets {
+			fmt.Printf("%d-%d: %d\n", b.From, b.To, b.Count)
 		}
 	}
-	fmt.Printf("Distance: %d\n", total)
 }
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"advent_of_code/common"
+	"fmt"
+	"slices"
+)
+
+type Galaxy struct {
+	// Number counts the galaxies from 1 in reading order.
+	Number int
+	common.Coord
+}
+
+type Pair struct {
+	First, Second Galaxy
+	Distance      int
+}
+
+type Bucket struct {
+	From, To int
+	Count    int
+}
+
+// Universe keeps the galaxies at their coordinates before the expansion,
+// along with the rows and columns without any galaxy, which expand.
+type Universe struct {
+	LenX, LenY int
+	Galaxies   []Galaxy
+	emptyX     []bool
+	emptyY     []bool
+}
+
+func NewUniverse(rows []string) (*Universe, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("empty universe")
+	}
+	u := &Universe{LenX: len(rows[0]), LenY: len(rows)}
+	u.emptyX = make([]bool, u.LenX)
+	u.emptyY = make([]bool, u.LenY)
+	for x := range u.emptyX {
+		u.emptyX[x] = true
+	}
+	for y, row := range rows {
+		if len(row) != u.LenX {
+			return nil, fmt.Errorf("row %d has %d tiles instead of %d", y+1, len(row), u.LenX)
+		}
+		u.emptyY[y] = true
+		for x, tile := range []Tile(row) {
+			switch tile {
+			case GALAXY:
+				u.Galaxies = append(u.Galaxies, Galaxy{len(u.Galaxies) + 1, common.Coord{X: x, Y: y}})
+				u.emptyX[x], u.emptyY[y] = false, false
+			case EMPTY_SPACE:
+			default:
+				return nil, fmt.Errorf("unknown tile %q at row %d, column %d", tile, y+1, x+1)
+			}
+		}
+	}
+	return u, nil
+}
+
+// expandedPositions maps every position along an axis to where it ends up
+// once every empty row or column is replaced by expansion of them.
+func expandedPositions(empty []bool, expansion int) []int {
+	positions := make([]int, len(empty))
+	position := 0
+	for i, isEmpty := range empty {
+		positions[i] = position
+		if isEmpty {
+			position += expansion
+		} else {
+			position++
+		}
+	}
+	return positions
+}
+
+// Expanded moves the galaxies to their coordinates after the expansion.
+func (u *Universe) Expanded(expansion int) []Galaxy {
+	xs := expandedPositions(u.emptyX, expansion)
+	ys := expandedPositions(u.emptyY, expansion)
+	galaxies := make([]Galaxy, len(u.Galaxies))
+	for i, g := range u.Galaxies {
+		galaxies[i] = Galaxy{g.Number, common.Coord{X: xs[g.X], Y: ys[g.Y]}}
+	}
+	return galaxies
+}
+
+func absInt(x int) int {
+	if x < 0 {
+		x = -x
+	}
+	return x
+}
+
+func manhattanDistance(first, second common.Coord) int {
+	return absInt(first.X-second.X) + absInt(first.Y-second.Y)
+}
+
+// sumOfGaps adds up the differences between all pairs of the values. Once
+// sorted, every value is larger than all the ones before it, so it adds
+// itself as many times as it has predecessors minus their sum.
+func sumOfGaps(values []int) int {
+	slices.Sort(values)
+	total, prefix := 0, 0
+	for i, v := range values {
+		total += v*i - prefix
+		prefix += v
+	}
+	return total
+}
+
+// Distances is the sum of the distances between all pairs of galaxies. The
+// Manhattan distance splits into the axes, each of them summed separately.
+func (u *Universe) Distances(expansion int) int {
+	galaxies := u.Expanded(expansion)
+	xs := make([]int, len(galaxies))
+	ys := make([]int, len(galaxies))
+	for i, g := range galaxies {
+		xs[i], ys[i] = g.X, g.Y
+	}
+	return sumOfGaps(xs) + sumOfGaps(ys)
+}
+
+// Pairs lists every pair of galaxies once, the first galaxy of the pair
+// having the lower number.
+func (u *Universe) Pairs(expansion int) []Pair {
+	galaxies := u.Expanded(expansion)
+	pairs := make([]Pair, 0, len(galaxies)*(len(galaxies)-1)/2)
+	for i := 0; i < len(galaxies)-1; i++ {
+		for j := i + 1; j < len(galaxies); j++ {
+			pairs = append(pairs, Pair{
+				First:    u.Galaxies[i],
+				Second:   u.Galaxies[j],
+				Distance: manhattanDistance(galaxies[i].Coord, galaxies[j].Coord),
+			})
+		}
+	}
+	return pairs
+}
+
+func compareDistances(a, b Pair) int {
+	return a.Distance - b.Distance
+}
+
+// NearestPair and FarthestPair keep the galaxies at their coordinates before
+// the expansion. Out of equally distant pairs, the first one listed by Pairs
+// wins.
+func (u *Universe) NearestPair(expansion int) (Pair, bool) {
+	pairs := u.Pairs(expansion)
+	if len(pairs) == 0 {
+		return Pair{}, false
+	}
+	return slices.MinFunc(pairs, compareDistances), true
+}
+
+func (u *Universe) FarthestPair(expansion int) (Pair, bool) {
+	pairs := u.Pairs(expansion)
+	if len(pairs) == 0 {
+		return Pair{}, false
+	}
+	return slices.MaxFunc(pairs, compareDistances), true
+}
+
+// MaxBuckets bounds the distribution, so that a small width with a large
+// expansion does not allocate a bucket for every distance.
+const MaxBuckets = 100000
+
+// DistanceDistribution counts the pairs by distance in buckets of the given
+// width, from the bucket of 0 up to the one of the farthest pair.
+func (u *Universe) DistanceDistribution(expansion int, width int) ([]Bucket, error) {
+	if width <= 0 {
+		return nil, fmt.Errorf("bucket width %d is not positive", width)
+	}
+	pairs := u.Pairs(expansion)
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	farthest := slices.MaxFunc(pairs, compareDistances)
+	count := farthest.Distance/width + 1
+	if count > MaxBuckets {
+		return nil, fmt.Errorf("bucket width %d makes %d buckets, more than %d", width, count, MaxBuckets)
+	}
+	buckets := make([]Bucket, count)
+	for i := range buckets {
+		buckets[i] = Bucket{From: i * width, To: (i+1)*width - 1}
+	}
+	for _, p := range pairs {
+		buckets[p.Distance/width].Count++
+	}
+	return buckets, nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"math/rand"
+)
+
+type runState struct {
+	group, run int
+}
+
+// Arrangements counts the ways to complete the springs bottom-up. The state
+// after scanning a prefix of the tiles is the number of finished groups and
+// the length of the run of broken springs in progress, ways[i][state] is the
+// number of valid completions of the tiles from i onward.
+type Arrangements struct {
+	tiles  []rune
+	groups []int
+	offset []int
+	ways   [][]int
+}
+
+func NewArrangements(tiles []rune, groups []int) *Arrangements {
+	a := &Arrangements{tiles: tiles, groups: groups}
+	a.offset = make([]int, len(groups)+1)
+	for g := 1; g <= len(groups); g++ {
+		a.offset[g] = a.offset[g-1] + groups[g-1] + 1
+	}
+	states := a.offset[len(groups)] + 1
+
+	a.ways = make([][]int, len(tiles)+1)
+	for i := range a.ways {
+		a.ways[i] = make([]int, states)
+	}
+	// Past the end, a state is complete if one more operational tile would
+	// finish all groups.
+	all := a.states()
+	for _, s := range all {
+		if next, ok := a.next(s, OPERATIONAL); ok && next.group == len(groups) {
+			a.ways[len(tiles)][a.index(s)] = 1
+		}
+	}
+	for i := len(tiles) - 1; i >= 0; i-- {
+		for _, s := range all {
+			total := 0
+			for _, c := range tileOptions(tiles[i]) {
+				if next, ok := a.next(s, c); ok {
+					total += a.ways[i+1][a.index(next)]
+				}
+			}
+			a.ways[i][a.index(s)] = total
+		}
+	}
+	return a
+}
+
+func CountArrangements(tiles []rune, groups []int) int {
+	return NewArrangements(tiles, groups).Count()
+}
+
+func tileOptions(tile rune) []rune {
+	if tile == UNKNOWN {
+		return []rune{OPERATIONAL, BROKEN}
+	}
+	return []rune{tile}
+}
+
+func (a *Arrangements) states() []runState {
+	states := make([]runState, 0, a.offset[len(a.groups)]+1)
+	for g, length := range a.groups {
+		for r := 0; r <= length; r++ {
+			states = append(states, runState{g, r})
+		}
+	}
+	return append(states, runState{len(a.groups), 0})
+}
+
+func (a *Arrangements) index(s runState) int {
+	return a.offset[s.group] + s.run
+}
+
+// next places a tile after state s, ok is false if it breaks the groups.
+func (a *Arrangements) next(s runState, tile rune) (runState, bool) {
+	switch tile {
+	case OPERATIONAL:
+		if s.run == 0 {
+			return s, true
+		}
+		if s.run == a.groups[s.group] {
+			return runState{s.group + 1, 0}, true
+		}
+	case BROKEN:
+		if s.group < len(a.groups) && s.run < a.groups[s.group] {
+			return runState{s.group, s.run + 1}, true
+		}
+	}
+	return s, false
+}
+
+func (a *Arrangements) Count() int {
+	return a.ways[0][a.index(runState{0, 0})]
+}
+
+// Enumerate lists up to limit concrete arrangements in lexicographic order of
+// the tiles, operational before broken. Branches without completions are never
+// entered, so it takes time proportional to the output. A limit below 0 is
+// the same as 0.
+func (a *Arrangements) Enumerate(limit int) [][]rune {
+	limit = max(limit, 0)
+	result := make([][]rune, 0, min(limit, a.Count()))
+	current := make([]rune, len(a.tiles))
+	var walk func(i int, s runState)
+	walk = func(i int, s runState) {
+		if len(result) >= limit {
+			return
+		}
+		if i == len(a.tiles) {
+			result = append(result, append([]rune(nil), current...))
+			return
+		}
+		for _, c := range tileOptions(a.tiles[i]) {
+			if next, ok := a.next(s, c); ok && a.ways[i+1][a.index(next)] > 0 {
+				current[i] = c
+				walk(i+1, next)
+			}
+		}
+	}
+	if a.Count() > 0 {
+		walk(0, runState{0, 0})
+	}
+	return result
+}
+
+// Sample picks one of the arrangements uniformly at random, or returns nil if
+// there are none.
+func (a *Arrangements) Sample(rng *rand.Rand) []rune {
+	if a.Count() == 0 {
+		return nil
+	}
+	arrangement := make([]rune, len(a.tiles))
+	s := runState{0, 0}
+	for i := range a.tiles {
+		pick := rng.Intn(a.ways[i][a.index(s)])
+		for _, c := range tileOptions(a.tiles[i]) {
+			next, ok := a.next(s, c)
+			if !ok {
+				continue
+			}
+			if ways := a.ways[i+1][a.index(next)]; pick >= ways {
+				pick -= ways
+				continue
+			}
+			arrangement[i], s = c, next
+			break
+		}
+	}
+	return arrangement
+}
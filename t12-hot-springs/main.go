@@ -2,9 +2,12 @@ package main
 
 import (
 	"advent_of_code/common"
+	"context"
+	"flag"
 	"fmt"
 	"github.com/samber/lo"
 	"log"
+	"math/rand"
 	"strconv"
 	"strings"
 )
@@ -15,6 +18,21 @@ const (
 	BROKEN      = '#'
 )
 
+const (
+	// Part 1 is 1 unfold.
+	Unfolds         = 5
+	UnfoldSeparator = UNKNOWN
+)
+
+var (
+	workers   = common.WorkersFlag()
+	timeout   = common.TimeoutFlag()
+	counter   = flag.String("counter", "dp", "count the arrangements with \"dp\", \"memo\", or \"both\" to check they agree")
+	enumerate = flag.Int("enumerate", 0, "print up to this many arrangements of every row")
+	sample    = flag.Int("sample", 0, "print this many arrangements of every row picked at random")
+	seed      = flag.Int64("seed", 1, "seed of the random arrangements")
+)
+
 type Springs struct {
 	Field           []rune
 	UnknownIndices  []int
@@ -29,17 +47,17 @@ func unfoldSlice[T any](s []T, times int) []T {
 	return newSlice
 }
 
-func unfoldTiles(tiles []rune, times int) []rune {
+func unfoldTiles(tiles []rune, times int, separator rune) []rune {
 	newSlice := make([]rune, 0, len(tiles)*times+(times-1))
 	for i := 0; i < times-1; i++ {
 		newSlice = append(newSlice, tiles...)
-		newSlice = append(newSlice, UNKNOWN)
+		newSlice = append(newSlice, separator)
 	}
 	newSlice = append(newSlice, tiles...)
 	return newSlice
 }
 
-func SprintsFromString(raw string, unfolds int) Springs {
+func SprintsFromString(raw string, unfolds int, separator rune) Springs {
 	// ????.######..#####. 1,6,5
 	components := strings.Fields(raw)
 	rawSequences := strings.Split(components[1], ",")
@@ -53,7 +71,7 @@ func SprintsFromString(raw string, unfolds int) Springs {
 	sequences = unfoldSlice(sequences, unfolds)
 	field := components[0]
 	fieldChars := []rune(field)
-	fieldChars = unfoldTiles(fieldChars, unfolds)
+	fieldChars = unfoldTiles(fieldChars, unfolds, separator)
 	unknownIndicesTuples := lo.Filter(lo.Map(fieldChars, func(c rune, index int) lo.Tuple2[rune, int] {
 		return lo.T2(c, index)
 	}), func(item lo.Tuple2[rune, int], index int) bool {
@@ -81,75 +99,112 @@ func ExistsBrokenRangeAt(tiles []rune, idx int, length int) bool {
 	return idx+length == len(tiles) || tiles[idx+length] != BROKEN
 }
 
-func CountCombinations(tiles []rune, brokenSeqs []int, tilesIdx int, brokenSeqsIdx int, cache *map[lo.Tuple2[int, int]]int) int {
-
-	solution, exists := (*cache)[lo.T2(tilesIdx, brokenSeqsIdx)]
-	if exists {
-		return solution
-	}
+type scanPosition struct {
+	tilesIdx, brokenSeqsIdx int
+}
 
-	if tilesIdx >= len(tiles) {
-		// Ended scan.
-		if brokenSeqsIdx == len(brokenSeqs) {
-			(*cache)[lo.T2(tilesIdx, brokenSeqsIdx)] = 1
-			return 1
-		} else {
-			(*cache)[lo.T2(tilesIdx, brokenSeqsIdx)] = 0
+func CountCombinations(tiles []rune, brokenSeqs []int) (int, common.MemoStats) {
+	countFrom, memo := common.Memoize(func(countFrom func(scanPosition) int, p scanPosition) int {
+		if p.tilesIdx >= len(tiles) {
+			// Ended scan.
+			if p.brokenSeqsIdx == len(brokenSeqs) {
+				return 1
+			}
 			return 0
 		}
-	}
 
-	switch tiles[tilesIdx] {
-	case OPERATIONAL:
-		count := CountCombinations(tiles, brokenSeqs, tilesIdx+1, brokenSeqsIdx, cache)
-		(*cache)[lo.T2(tilesIdx, brokenSeqsIdx)] = count
-		return count
-	case BROKEN:
-		count := computeBroken(tiles, brokenSeqs, tilesIdx, brokenSeqsIdx, cache)
-		(*cache)[lo.T2(tilesIdx, brokenSeqsIdx)] = count
-		return count
-	case UNKNOWN:
-		ifBrokenCount := computeBroken(tiles, brokenSeqs, tilesIdx, brokenSeqsIdx, cache)
-		ifOperationalCount := CountCombinations(tiles, brokenSeqs, tilesIdx+1, brokenSeqsIdx, cache)
-		(*cache)[lo.T2(tilesIdx, brokenSeqsIdx)] = ifOperationalCount + ifBrokenCount
-		return ifOperationalCount + ifBrokenCount
-	}
-	log.Fatalf("Unreacheable")
-	return 0
-}
+		ifOperational := func() int {
+			return countFrom(scanPosition{p.tilesIdx + 1, p.brokenSeqsIdx})
+		}
+		ifBroken := func() int {
+			if p.brokenSeqsIdx >= len(brokenSeqs) {
+				return 0
+			}
+			length := brokenSeqs[p.brokenSeqsIdx]
+			if !ExistsBrokenRangeAt(tiles, p.tilesIdx, length) {
+				return 0
+			}
+			return countFrom(scanPosition{p.tilesIdx + length + 1, p.brokenSeqsIdx + 1})
+		}
 
-func computeBroken(tiles []rune, brokenSeqs []int, tilesIdx int, brokenSeqsIdx int, cache *map[lo.Tuple2[int, int]]int) int {
-	if brokenSeqsIdx >= len(brokenSeqs) {
+		switch tiles[p.tilesIdx] {
+		case OPERATIONAL:
+			return ifOperational()
+		case BROKEN:
+			return ifBroken()
+		case UNKNOWN:
+			return ifOperational() + ifBroken()
+		}
+		log.Fatalf("Unexpected tile %c", tiles[p.tilesIdx])
 		return 0
-	}
+	})
+	return countFrom(scanPosition{0, 0}), memo.Stats()
+}
 
-	if ExistsBrokenRangeAt(tiles, tilesIdx, brokenSeqs[brokenSeqsIdx]) {
-		return CountCombinations(tiles, brokenSeqs, tilesIdx+brokenSeqs[brokenSeqsIdx]+1, brokenSeqsIdx+1, cache)
+func countRow(s Springs) (int, common.MemoStats, error) {
+	switch *counter {
+	case "dp":
+		return CountArrangements(s.Field, s.BrokenSequences), common.MemoStats{}, nil
+	case "memo":
+		count, stats := CountCombinations(s.Field, s.BrokenSequences)
+		return count, stats, nil
+	}
+	count := CountArrangements(s.Field, s.BrokenSequences)
+	memoCount, stats := CountCombinations(s.Field, s.BrokenSequences)
+	if memoCount != count {
+		return 0, stats, fmt.Errorf("bottom-up count %d differs from the memoized %d for %s", count, memoCount, string(s.Field))
 	}
-	return 0
+	return count, stats, nil
 }
 
 func main() {
+	flag.Parse()
+	if *counter != "dp" && *counter != "memo" && *counter != "both" {
+		log.Fatalf("Unknown counter %q", *counter)
+	}
+	ctx, cancel := common.SolverContext(*timeout)
+	defer cancel()
+
 	//rawSprings, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t12-hot-springs/test.txt")
 	rawSprings, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t12-hot-springs/1.txt")
 	if err != nil {
-		log.Fatalf("Failed to read file: %w", err)
+		log.Fatalf("Failed to read file: %v", err)
 	}
 
 	springs := lo.Map(rawSprings, common.NoIndex(func(s string) Springs {
-		return SprintsFromString(s, 5)
+		return SprintsFromString(s, Unfolds, UnfoldSeparator)
 	}))
-	fmt.Printf("%d", lo.Sum(lo.Map(springs, func(s Springs, index int) int {
-		cache := make(map[lo.Tuple2[int, int]]int)
-		return CountCombinations(s.Field, s.BrokenSequences, 0, 0, &cache)
-	})))
-	//cache := make(map[lo.Tuple2[int, int]]int)
-	//fmt.Printf("%d", CountCombinations(
-	//	[]rune{UNKNOWN, UNKNOWN, UNKNOWN, OPERATIONAL, BROKEN, BROKEN, BROKEN},
-	//	[]int{1, 1, 3},
-	//	0, 0, &cache))
-	//fmt.Printf("%d", CountCombinations(
-	//	[]rune{UNKNOWN, UNKNOWN, UNKNOWN, OPERATIONAL},
-	//	[]int{1, 1},
-	//	0, 0, &cache))
+	type rowResult struct {
+		count int
+		stats common.MemoStats
+	}
+	results, err := common.ParallelMap(ctx, springs, *workers, func(ctx context.Context, s Springs) (rowResult, error) {
+		count, stats, err := countRow(s)
+		return rowResult{count, stats}, err
+	})
+	if err != nil {
+		log.Fatalf("Failed to count arrangements: %v", err)
+	}
+
+	total := 0
+	var stats common.MemoStats
+	rng := rand.New(rand.NewSource(*seed))
+	for i, s := range springs {
+		if *enumerate > 0 || *sample > 0 {
+			fmt.Printf("%s %v: %d\n", string(s.Field), s.BrokenSequences, results[i].count)
+			arrangements := NewArrangements(s.Field, s.BrokenSequences)
+			for _, arrangement := range arrangements.Enumerate(*enumerate) {
+				fmt.Printf("  %s\n", string(arrangement))
+			}
+			for j := 0; j < *sample && arrangements.Count() > 0; j++ {
+				fmt.Printf("  random %s\n", string(arrangements.Sample(rng)))
+			}
+		}
+		total += results[i].count
+		stats = stats.Add(results[i].stats)
+	}
+	fmt.Printf("%d", total)
+	if *counter != "dp" {
+		fmt.Printf("\nCache %s\n", stats)
+	}
 }
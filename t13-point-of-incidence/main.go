@@ -2,12 +2,11 @@ package main
 
 import (
 	"advent_of_code/common"
+	"context"
+	"flag"
 	"fmt"
 	"github.com/samber/lo"
-	"io"
 	"log"
-	"os"
-	"strings"
 )
 
 type Direction int
@@ -73,8 +72,12 @@ func CheckYMirror(m [][]rune, atY int, requiredErrors int) bool {
 	return requiredErrors == encounteredErrors
 }
 
-func findIntersections(rawMap string, requiredErrors int) []Intersection {
-	rowsAsStrings := strings.Split(rawMap, "\n")
+var (
+	workers = common.WorkersFlag()
+	timeout = common.TimeoutFlag()
+)
+
+func findIntersections(rowsAsStrings []string, requiredErrors int) []Intersection {
 	rows := lo.Map(rowsAsStrings, common.NoIndex(func(s string) []rune {
 		return []rune(s)
 	}))
@@ -93,20 +96,26 @@ func findIntersections(rawMap string, requiredErrors int) []Intersection {
 }
 
 func main() {
-	file, err := os.Open("/Users/iv/Code/advent-of-code-2023/t13-point-of-incidence/1.txt")
-	//file, err := os.Open("/Users/iv/Code/advent-of-code-2023/t13-point-of-incidence/test.txt")
+	flag.Parse()
+	ctx, cancel := common.SolverContext(*timeout)
+	defer cancel()
+
+	rawMaps, err := common.OpenBlocks("/Users/iv/Code/advent-of-code-2023/t13-point-of-incidence/1.txt")
+	//rawMaps, err := common.OpenBlocks("/Users/iv/Code/advent-of-code-2023/t13-point-of-incidence/test.txt")
 	if err != nil {
 		log.Fatalf("Failed to open file")
 	}
-	allMaps, err := io.ReadAll(file)
+	maps, err := rawMaps.Collect()
 	if err != nil {
-		log.Fatalf("Failed to read file")
+		log.Fatalf("Failed to read file: %v", err)
 	}
-	rawMaps := strings.Split(string(allMaps), "\n\n")
-	intersections := lo.FlatMap(rawMaps, func(m string, index int) []Intersection {
-		return findIntersections(m, 1)
+	intersections, err := common.ParallelMap(ctx, maps, *workers, func(ctx context.Context, m []string) ([]Intersection, error) {
+		return findIntersections(m, 1), nil
 	})
-	fmt.Printf("%d\n", lo.SumBy(intersections, func(i Intersection) int {
+	if err != nil {
+		log.Fatalf("Failed to find intersections: %v", err)
+	}
+	fmt.Printf("%d\n", lo.SumBy(lo.Flatten(intersections), func(i Intersection) int {
 		return i.ToValue()
 	}))
 }
@@ -2,8 +2,10 @@ package main
 
 import (
 	"advent_of_code/common"
+	"context"
 	"crypto/md5"
 	"encoding/hex"
+	"flag"
 	"fmt"
 	"github.com/samber/lo"
 	"log"
@@ -18,6 +20,8 @@ const (
 	SPACE Tile = '.'
 )
 
+var timeout = common.TimeoutFlag()
+
 type Field struct {
 	tiles [][]Tile
 }
@@ -100,32 +104,26 @@ func (f Field) CalculateScore() int {
 	return total
 }
 
-func main() {
-	//rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t14-reflector/test.txt")
-	rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t14-reflector/1.txt")
-	if err != nil {
-		log.Fatalf("Couldn't read file %w", err)
-	}
-	field := Field{
-		tiles: lo.Map(rows, common.NoIndex(func(s string) []Tile {
-			return []Tile(s)
-		})),
-	}
+// SpinCycles tilts the field north, west, south and east the given number of
+// times, skipping ahead once the field repeats itself.
+func (f *Field) SpinCycles(ctx context.Context, rotations int) error {
 	hashes := make(map[string]int, 0)
-	hashes[field.Hash()] = 0
-	rotations := 1000000000
+	hashes[f.Hash()] = 0
 	//var cycleLength int
 	for i := 0; i < rotations; i++ {
-		field.RollTo(0, -1)
-		//fmt.Printf("%s\n", field.ToString())
-		field.RollTo(-1, 0)
-		//fmt.Printf("%s\n", field.ToString())
-		field.RollTo(0, 1)
-		//fmt.Printf("%s\n", field.ToString())
-		field.RollTo(1, 0)
-		//fmt.Printf("%s\n", field.ToString())
-
-		currentHash := field.Hash()
+		if err := common.CheckInterrupted(ctx, "%d of %d cycles", i, rotations); err != nil {
+			return err
+		}
+		f.RollTo(0, -1)
+		//fmt.Printf("%s\n", f.ToString())
+		f.RollTo(-1, 0)
+		//fmt.Printf("%s\n", f.ToString())
+		f.RollTo(0, 1)
+		//fmt.Printf("%s\n", f.ToString())
+		f.RollTo(1, 0)
+		//fmt.Printf("%s\n", f.ToString())
+
+		currentHash := f.Hash()
 		prevIndex, exists := hashes[currentHash]
 		if exists {
 			cycleLength := i - prevIndex
@@ -136,6 +134,27 @@ func main() {
 			hashes[currentHash] = i
 		}
 	}
+	return nil
+}
+
+func main() {
+	flag.Parse()
+	ctx, cancel := common.SolverContext(*timeout)
+	defer cancel()
+
+	//rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t14-reflector/test.txt")
+	rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t14-reflector/1.txt")
+	if err != nil {
+		log.Fatalf("Couldn't read file %v", err)
+	}
+	field := Field{
+		tiles: lo.Map(rows, common.NoIndex(func(s string) []Tile {
+			return []Tile(s)
+		})),
+	}
+	if err := field.SpinCycles(ctx, 1000000000); err != nil {
+		log.Fatalf("Failed to spin: %v", err)
+	}
 	//field.RollTo(0, -1)
 
 	fmt.Printf("Total: %d\n", field.CalculateScore())
@@ -1,15 +1,18 @@
 package main
 
 import (
+	"advent_of_code/common"
+	"flag"
 	"fmt"
 	"github.com/samber/lo"
 	"io"
 	"log"
 	"os"
-	"strconv"
 	"strings"
 )
 
+var replay = flag.Bool("replay", false, "print the boxes after every step")
+
 func HashString(s string) int {
 	return HashRunes([]rune(s))
 }
@@ -46,50 +49,115 @@ func IndexOfWord(items []Item, word string) (int, bool) {
 	return 0, false
 }
 
-func (m *HashMap) ProcessCommand(cmd string) {
-	// rn=1,cm-
-	if strings.Contains(cmd, "=") {
-		components := strings.Split(cmd, "=")
-		word := components[0]
-		focalLength, _ := strconv.Atoi(components[1])
-		hash := HashString(word)
-		index, found := IndexOfWord(m.lenses[hash], word)
-		if found {
-			m.lenses[hash][index].value = focalLength
-		} else {
-			m.lenses[hash] = append(m.lenses[hash], Item{word, focalLength})
-		}
-	} else if strings.Contains(cmd, "-") {
-		components := strings.Split(cmd, "-")
-		word := components[0]
-		hash := HashString(word)
-		index, found := IndexOfWord(m.lenses[hash], word)
-		if found {
-			m.lenses[hash] = append(m.lenses[hash][:index], m.lenses[hash][index+1:]...)
-		}
+func (m *HashMap) Put(label string, focalLength int) {
+	hash := HashString(label)
+	index, found := IndexOfWord(m.lenses[hash], label)
+	if found {
+		m.lenses[hash][index].value = focalLength
 	} else {
-		log.Fatalf("Invalid command")
+		m.lenses[hash] = append(m.lenses[hash], Item{label, focalLength})
 	}
 }
 
-func (m HashMap) TotalPower() int {
-	total := 0
+func (m *HashMap) Remove(label string) bool {
+	hash := HashString(label)
+	index, found := IndexOfWord(m.lenses[hash], label)
+	if found {
+		m.lenses[hash] = append(m.lenses[hash][:index], m.lenses[hash][index+1:]...)
+	}
+	return found
+}
+
+func (m *HashMap) Get(label string) (int, bool) {
+	hash := HashString(label)
+	index, found := IndexOfWord(m.lenses[hash], label)
+	if !found {
+		return 0, false
+	}
+	return m.lenses[hash][index].value, true
+}
+
+// Each visits lenses in box order, and within a box in insertion order.
+func (m *HashMap) Each(visitor func(box, slot int, item Item)) {
 	for idx, cell := range m.lenses {
-		for boxIdx, item := range cell {
-			total += (idx + 1) * (boxIdx + 1) * item.value
+		for slotIdx, item := range cell {
+			visitor(idx, slotIdx, item)
 		}
 	}
+}
+
+func (m *HashMap) Len() int {
+	total := 0
+	for _, cell := range m.lenses {
+		total += len(cell)
+	}
 	return total
 }
 
+func (m *HashMap) FocusingPower() int {
+	total := 0
+	m.Each(func(box, slot int, item Item) {
+		total += (box + 1) * (slot + 1) * item.value
+	})
+	return total
+}
+
+func (m *HashMap) String() string {
+	var sb strings.Builder
+	for idx, cell := range m.lenses {
+		if len(cell) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "Box %d:", idx)
+		for _, item := range cell {
+			fmt.Fprintf(&sb, " [%s %d]", item.key, item.value)
+		}
+		sb.WriteRune('\n')
+	}
+	return sb.String()
+}
+
+func (m *HashMap) Apply(step Step) {
+	switch step.Op {
+	case OpPut:
+		m.Put(step.Label, step.FocalLength)
+	case OpRemove:
+		m.Remove(step.Label)
+	}
+}
+
+// Replay applies the steps one by one, printing the boxes after each of them
+// the same way the puzzle statement does.
+func (m *HashMap) Replay(w io.Writer, steps []Step) error {
+	for _, step := range steps {
+		m.Apply(step)
+		if _, err := fmt.Fprintf(w, "After \"%s\":\n%s\n", step, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func main() {
-	file, _ := os.Open("/Users/iv/Code/advent-of-code-2023/t15-lens-library/1.txt")
-	//file, _ := os.Open("/Users/iv/Code/advent-of-code-2023/t15-lens-library/test.txt")
-	rawChars, _ := io.ReadAll(file)
-	steps := strings.Split(strings.TrimSpace(string(rawChars)), ",")
+	flag.Parse()
+	records, err := common.OpenRecords("/Users/iv/Code/advent-of-code-2023/t15-lens-library/1.txt", ",")
+	//records, err := common.OpenRecords("/Users/iv/Code/advent-of-code-2023/t15-lens-library/test.txt", ",")
+	if err != nil {
+		log.Fatalf("Failed to open file: %v", err)
+	}
+	steps, err := ParseSteps(records)
+	if err != nil {
+		log.Fatalf("Failed to parse steps: %v", err)
+	}
 	hm := NewHashMap()
-	for _, step := range steps {
-		hm.ProcessCommand(step)
+	if *replay {
+		if err := hm.Replay(os.Stdout, steps); err != nil {
+			log.Fatalf("Failed to print the replay: %v", err)
+		}
+	} else {
+		for _, step := range steps {
+			hm.Apply(step)
+		}
 	}
-	fmt.Printf("Total: %d\n", hm.TotalPower())
+	fmt.Printf("Total: %d\n", hm.FocusingPower())
 }
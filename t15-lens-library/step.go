@@ -0,0 +1,83 @@
+package main
+
+import (
+	"advent_of_code/common"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	OpPut    = '='
+	OpRemove = '-'
+)
+
+type Step struct {
+	Label       string
+	Op          byte
+	FocalLength int
+}
+
+func (s Step) String() string {
+	if s.Op == OpPut {
+		return fmt.Sprintf("%s=%d", s.Label, s.FocalLength)
+	}
+	return s.Label + "-"
+}
+
+type StepError struct {
+	Index int
+	Step  string
+	Err   error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("step %d %q: %v", e.Index, e.Step, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// ParseStep parses a single instruction like "rn=1" or "cm-".
+func ParseStep(raw string) (Step, error) {
+	if label, focal, found := strings.Cut(raw, "="); found {
+		if label == "" {
+			return Step{}, fmt.Errorf("empty label")
+		}
+		focalLength, err := strconv.Atoi(focal)
+		if err != nil {
+			return Step{}, fmt.Errorf("invalid focal length %q", focal)
+		}
+		if focalLength < 1 || focalLength > 9 {
+			return Step{}, fmt.Errorf("focal length %d is out of 1..9", focalLength)
+		}
+		return Step{Label: label, Op: OpPut, FocalLength: focalLength}, nil
+	}
+	if label, found := strings.CutSuffix(raw, "-"); found {
+		if label == "" {
+			return Step{}, fmt.Errorf("empty label")
+		}
+		return Step{Label: label, Op: OpRemove}, nil
+	}
+	return Step{}, fmt.Errorf("no operation")
+}
+
+// ParseSteps parses the initialization sequence streamed as comma-separated
+// records.
+func ParseSteps(records *common.Iterator[string]) ([]Step, error) {
+	steps := make([]Step, 0)
+	for i := 0; records.Next(); i++ {
+		raw := records.Value()
+		step, err := ParseStep(raw)
+		if err != nil {
+			records.Close()
+			return nil, &StepError{Index: i, Step: raw, Err: err}
+		}
+		steps = append(steps, step)
+	}
+	if err := records.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read steps: %w", err)
+	}
+	return steps, nil
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"advent_of_code/common"
+	"fmt"
+	"github.com/samber/lo"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Recorder keeps every propagation step of a beam simulation so that it can be
+// replayed later. Energized tiles are stored as the step they were first
+// reached at, which is enough to reconstruct any intermediate frame.
+type Recorder struct {
+	field       BeamField
+	frames      [][]BeamCoord
+	energizedAt map[Coord]int
+	// energizedCounts holds the number of energized tiles at every frame.
+	energizedCounts []int
+}
+
+func RecordPropagation(field BeamField, coords []BeamCoord) *Recorder {
+	r := &Recorder{
+		field:       field,
+		energizedAt: make(map[Coord]int),
+	}
+	encounteredHashes := make(map[uint64]struct{})
+	hash := HashCoords(coords)
+	exists := false
+
+	for !exists {
+		for _, c := range coords {
+			if _, already := r.energizedAt[Coord{c.x, c.y}]; !already {
+				r.energizedAt[Coord{c.x, c.y}] = len(r.frames)
+			}
+		}
+		r.frames = append(r.frames, coords)
+		r.energizedCounts = append(r.energizedCounts, len(r.energizedAt))
+		encounteredHashes[hash] = struct{}{}
+		coords = lo.FlatMap(coords, func(c BeamCoord, index int) []BeamCoord {
+			return c.Step(r.field)
+		})
+		coords = lo.Uniq(coords)
+		slices.SortFunc(coords, CompareBeamCoord)
+
+		hash = HashCoords(coords)
+		_, exists = encounteredHashes[hash]
+	}
+	return r
+}
+
+func (r *Recorder) FrameCount() int {
+	return len(r.frames)
+}
+
+func (r *Recorder) isEnergized(c Coord, frame int) bool {
+	step, ok := r.energizedAt[c]
+	return ok && step <= frame
+}
+
+const (
+	ansiClear     = "\x1b[H\x1b[2J"
+	ansiReset     = "\x1b[0m"
+	ansiEnergized = "\x1b[30;43m"
+	ansiBeamHead  = "\x1b[1;31;43m"
+)
+
+func (r *Recorder) RenderAnsiFrame(frame int) string {
+	heads := r.frameHeads(frame)
+
+	var sb strings.Builder
+	for y, row := range r.field.tiles {
+		for x, tile := range row {
+			c := Coord{x, y}
+			if tileHeads := headsAt(heads, c); len(tileHeads) > 0 {
+				sb.WriteString(ansiBeamHead)
+				if len(tileHeads) == 1 {
+					sb.WriteRune(tileHeads[0].Char)
+				} else {
+					// Crossing beams show how many of them there are.
+					fmt.Fprintf(&sb, "%d", len(tileHeads))
+				}
+				sb.WriteString(ansiReset)
+			} else if r.isEnergized(c, frame) {
+				sb.WriteString(ansiEnergized)
+				sb.WriteRune(tile)
+				sb.WriteString(ansiReset)
+			} else {
+				sb.WriteRune(tile)
+			}
+		}
+		sb.WriteRune('\n')
+	}
+	return sb.String()
+}
+
+func (r *Recorder) PlayAnsi(w io.Writer, delay time.Duration) error {
+	for i := range r.frames {
+		_, err := fmt.Fprintf(w, "%s%sStep %d/%d, energized: %d\n",
+			ansiClear, r.RenderAnsiFrame(i), i+1, len(r.frames), r.energizedCount(i))
+		if err != nil {
+			return fmt.Errorf("failed to write frame %d: %w", i, err)
+		}
+		time.Sleep(delay)
+	}
+	return nil
+}
+
+func (r *Recorder) energizedCount(frame int) int {
+	return r.energizedCounts[frame]
+}
+
+var beamDirections = []common.DirectionDesc{directions.Up, directions.Down, directions.Left, directions.Right}
+
+// headsAt lists the directions of the beams of the frame on the tile, several
+// beams can cross the same tile.
+func headsAt(heads map[BeamCoord]struct{}, c Coord) []common.DirectionDesc {
+	var result []common.DirectionDesc
+	for _, direction := range beamDirections {
+		if _, ok := heads[BeamCoord{direction, c.x, c.y}]; ok {
+			result = append(result, direction)
+		}
+	}
+	return result
+}
+
+func (r *Recorder) frameHeads(frame int) map[BeamCoord]struct{} {
+	heads := make(map[BeamCoord]struct{}, len(r.frames[frame]))
+	for _, b := range r.frames[frame] {
+		heads[b] = struct{}{}
+	}
+	return heads
+}
+
+const (
+	gifBackground = iota
+	gifTile
+	gifEnergized
+	gifBeamHead
+)
+
+var gifPalette = color.Palette{
+	gifBackground: color.RGBA{0x10, 0x10, 0x18, 0xff},
+	gifTile:       color.RGBA{0x90, 0x90, 0x90, 0xff},
+	gifEnergized:  color.RGBA{0xf0, 0xc0, 0x30, 0xff},
+	gifBeamHead:   color.RGBA{0xe0, 0x20, 0x20, 0xff},
+}
+
+// tileGlyph reports whether the pixel (px, py) of a cell of the given size
+// belongs to the drawing of the tile.
+func tileGlyph(tile rune, px, py, size int) bool {
+	mid := size / 2
+	switch tile {
+	case '|':
+		return px == mid
+	case '-':
+		return py == mid
+	case '/':
+		return px+py == size-1
+	case '\\':
+		return px == py
+	}
+	return false
+}
+
+// arrowGlyph reports whether the pixel (px, py) of a cell of the given size
+// belongs to a triangle pointing in the given direction.
+func arrowGlyph(direction common.DirectionDesc, px, py, size int) bool {
+	mid := size / 2
+	switch direction.Name {
+	case common.RIGHT:
+		return size-1-px >= abs(py-mid)
+	case common.LEFT:
+		return px >= abs(py-mid)
+	case common.DOWN:
+		return size-1-py >= abs(px-mid)
+	case common.UP:
+		return py >= abs(px-mid)
+	}
+	return false
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func (r *Recorder) RenderGifFrame(frame int, cellSize int) *image.Paletted {
+	width, height := len(r.field.tiles[0]), len(r.field.tiles)
+	img := image.NewPaletted(image.Rect(0, 0, width*cellSize, height*cellSize), gifPalette)
+	heads := r.frameHeads(frame)
+
+	for y, row := range r.field.tiles {
+		for x, tile := range row {
+			c := Coord{x, y}
+			tileHeads := headsAt(heads, c)
+			background := uint8(gifBackground)
+			if r.isEnergized(c, frame) {
+				background = gifEnergized
+			}
+			for py := 0; py < cellSize; py++ {
+				for px := 0; px < cellSize; px++ {
+					index := background
+					if lo.SomeBy(tileHeads, func(d common.DirectionDesc) bool {
+						return arrowGlyph(d, px, py, cellSize)
+					}) {
+						index = gifBeamHead
+					} else if tileGlyph(tile, px, py, cellSize) {
+						index = gifTile
+					}
+					img.SetColorIndex(x*cellSize+px, y*cellSize+py, index)
+				}
+			}
+		}
+	}
+	return img
+}
+
+func (r *Recorder) WriteGif(w io.Writer, delay time.Duration, cellSize int) error {
+	anim := &gif.GIF{}
+	// GIF delays are measured in hundredths of a second, and a delay of 0 lets
+	// the viewer pick its own.
+	delayUnits := max(1, int(delay/(10*time.Millisecond)))
+	for i := range r.frames {
+		anim.Image = append(anim.Image, r.RenderGifFrame(i, cellSize))
+		anim.Delay = append(anim.Delay, delayUnits)
+	}
+	if err := gif.EncodeAll(w, anim); err != nil {
+		return fmt.Errorf("failed to encode gif: %w", err)
+	}
+	return nil
+}
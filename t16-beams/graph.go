@@ -0,0 +1,227 @@
+package main
+
+import (
+	"advent_of_code/common"
+	"context"
+	"github.com/samber/lo"
+	"math/bits"
+)
+
+type TileSet []uint64
+
+func NewTileSet(size int) TileSet {
+	return make(TileSet, (size+63)/64)
+}
+
+func (s TileSet) Add(i int) {
+	s[i/64] |= 1 << (i % 64)
+}
+
+func (s TileSet) Union(other TileSet) {
+	for i := range s {
+		s[i] |= other[i]
+	}
+}
+
+func (s TileSet) Len() int {
+	count := 0
+	for _, word := range s {
+		count += bits.OnesCount64(word)
+	}
+	return count
+}
+
+// Segment is the part of a beam path between two splits: it starts either at
+// the field edge or right after a splitter, and ends when the beam leaves the
+// field, loops onto itself or gets split again.
+type Segment struct {
+	tiles TileSet
+	// Index of the splitter node the segment ends at, -1 if none.
+	next int
+}
+
+type splitterNode struct {
+	x, y     int
+	outgoing []Segment
+}
+
+type SegmentGraph struct {
+	field      BeamField
+	nodes      []splitterNode
+	tileToNode map[Coord]int
+
+	// Condensation of the splitter graph: every node belongs to exactly one
+	// strongly connected component, and every component knows all the tiles
+	// energized by a beam that reaches any of its splitters.
+	nodeToComponent []int
+	componentTiles  []TileSet
+}
+
+func (f BeamField) tileIndex(x, y int) int {
+	return y*len(f.tiles[0]) + x
+}
+
+func (f BeamField) tileCount() int {
+	return len(f.tiles) * len(f.tiles[0])
+}
+
+func isSplitting(tile rune, direction common.DirectionDesc) bool {
+	switch tile {
+	case '|':
+		return direction.DeltaX != 0
+	case '-':
+		return direction.DeltaY != 0
+	}
+	return false
+}
+
+func (g *SegmentGraph) trace(start BeamCoord) Segment {
+	segment := Segment{tiles: NewTileSet(g.field.tileCount()), next: -1}
+	visited := make(map[BeamCoord]struct{})
+	beam := start
+	for beam.WithinField(g.field) {
+		if _, already := visited[beam]; already {
+			break
+		}
+		visited[beam] = struct{}{}
+		tile := g.field.tiles[beam.y][beam.x]
+		if isSplitting(tile, beam.direction) {
+			segment.next = g.tileToNode[Coord{beam.x, beam.y}]
+			break
+		}
+		segment.tiles.Add(g.field.tileIndex(beam.x, beam.y))
+		next := beam.Step(g.field)
+		if len(next) == 0 {
+			break
+		}
+		beam = next[0]
+	}
+	return segment
+}
+
+func NewSegmentGraph(field BeamField) *SegmentGraph {
+	g := &SegmentGraph{
+		field:      field,
+		tileToNode: make(map[Coord]int),
+	}
+	for y, row := range field.tiles {
+		for x, tile := range row {
+			if tile == '|' || tile == '-' {
+				g.tileToNode[Coord{x, y}] = len(g.nodes)
+				g.nodes = append(g.nodes, splitterNode{x: x, y: y})
+			}
+		}
+	}
+	for i := range g.nodes {
+		node := &g.nodes[i]
+		var outputs []BeamCoord
+		if field.tiles[node.y][node.x] == '|' {
+			outputs = []BeamCoord{{directions.Up, node.x, node.y}, {directions.Down, node.x, node.y}}
+		} else {
+			outputs = []BeamCoord{{directions.Left, node.x, node.y}, {directions.Right, node.x, node.y}}
+		}
+		for _, out := range outputs {
+			next := out.FlyForward(field)
+			if len(next) == 0 {
+				continue
+			}
+			node.outgoing = append(node.outgoing, g.trace(next[0]))
+		}
+	}
+	g.condense()
+	return g
+}
+
+// condense finds strongly connected components with Tarjan's algorithm. The
+// algorithm emits components in reverse topological order, so by the time a
+// component is emitted all components reachable from it already have their
+// energized tiles computed and can be reused.
+func (g *SegmentGraph) condense() {
+	index := 0
+	indices := make([]int, len(g.nodes))
+	lowLinks := make([]int, len(g.nodes))
+	onStack := make([]bool, len(g.nodes))
+	stack := make([]int, 0)
+	g.nodeToComponent = make([]int, len(g.nodes))
+	for i := range g.nodes {
+		indices[i] = -1
+	}
+
+	var strongConnect func(v int)
+	strongConnect = func(v int) {
+		indices[v] = index
+		lowLinks[v] = index
+		index++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, segment := range g.nodes[v].outgoing {
+			w := segment.next
+			if w == -1 {
+				continue
+			}
+			if indices[w] == -1 {
+				strongConnect(w)
+				lowLinks[v] = min(lowLinks[v], lowLinks[w])
+			} else if onStack[w] {
+				lowLinks[v] = min(lowLinks[v], indices[w])
+			}
+		}
+
+		if lowLinks[v] != indices[v] {
+			return
+		}
+		component := len(g.componentTiles)
+		members := make([]int, 0)
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			g.nodeToComponent[w] = component
+			members = append(members, w)
+			if w == v {
+				break
+			}
+		}
+		tiles := NewTileSet(g.field.tileCount())
+		for _, m := range members {
+			node := g.nodes[m]
+			tiles.Add(g.field.tileIndex(node.x, node.y))
+			for _, segment := range node.outgoing {
+				tiles.Union(segment.tiles)
+				if segment.next != -1 && g.nodeToComponent[segment.next] != component {
+					tiles.Union(g.componentTiles[g.nodeToComponent[segment.next]])
+				}
+			}
+		}
+		g.componentTiles = append(g.componentTiles, tiles)
+	}
+
+	for i := range g.nodes {
+		if indices[i] == -1 {
+			strongConnect(i)
+		}
+	}
+}
+
+func (g *SegmentGraph) Energized(starts []BeamCoord) int {
+	tiles := NewTileSet(g.field.tileCount())
+	for _, start := range starts {
+		segment := g.trace(start)
+		tiles.Union(segment.tiles)
+		if segment.next != -1 {
+			tiles.Union(g.componentTiles[g.nodeToComponent[segment.next]])
+		}
+	}
+	return tiles.Len()
+}
+
+func MaxEnergized(ctx context.Context, g *SegmentGraph, configurations [][]BeamCoord, workers int) (int, error) {
+	energized, err := common.ParallelMap(ctx, configurations, workers, func(ctx context.Context, coords []BeamCoord) (int, error) {
+		return g.Energized(coords), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return lo.Max(energized), nil
+}
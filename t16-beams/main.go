@@ -3,64 +3,37 @@ package main
 import (
 	"advent_of_code/common"
 	"encoding/binary"
+	"flag"
 	"fmt"
 	"github.com/samber/lo"
 	"hash/fnv"
+	"io"
 	"log"
+	"os"
 	"slices"
 	"strings"
-	"sync"
+	"time"
 )
 
-type Direction struct {
-	deltaX, deltaY int
-	name           string
-}
-type Coord struct {
-	x, y int
-}
+var directions = common.NewDirections()
 
-type DirectionName string
+// GifCellSize is the side in pixels of a tile of the gif animation.
+const GifCellSize = 6
 
-const (
-	UP    DirectionName = "UP"
-	DOWN  DirectionName = "DOWN"
-	LEFT  DirectionName = "LEFT"
-	RIGHT DirectionName = "RIGHT"
+var (
+	workers         = common.WorkersFlag()
+	timeout         = common.TimeoutFlag()
+	animation       = flag.String("animation", "", "record the beams from the top left as \"gif\" or \"ansi\"")
+	animationOutput = flag.String("animation-output", "", "write the animation to this file, required for gif")
+	frameDelay      = flag.Duration("frame-delay", 50*time.Millisecond, "delay between the frames of the animation")
 )
 
-func DirectionNameToChar(d DirectionName) rune {
-	switch d {
-	case UP:
-		return '^'
-	case LEFT:
-		return '<'
-	case DOWN:
-		return 'v'
-	case RIGHT:
-		return '>'
-	}
-	log.Fatalf("dfsdfsd")
-	return '0'
-}
-
-func DirectionToDelta(d DirectionName) (int, int) {
-	switch d {
-	case UP:
-		return 0, -1
-	case DOWN:
-		return 0, 1
-	case LEFT:
-		return -1, 0
-	case RIGHT:
-		return 1, 0
-	}
-	log.Fatalf("Unreachible")
-	return 0, 0
+type Coord struct {
+	x, y int
 }
 
 type BeamCoord struct {
-	direction DirectionName
+	direction common.DirectionDesc
 	x, y      int
 }
 
@@ -71,35 +44,33 @@ func CompareBeamCoord(a, b BeamCoord) int {
 	if a.y != b.y {
 		return a.y - b.y
 	}
-	aDeltaX, aDeltaY := DirectionToDelta(a.direction)
-	bDeltaX, bDeltaY := DirectionToDelta(b.direction)
-	if aDeltaX != bDeltaX {
-		return aDeltaX - bDeltaX
+	if a.direction.DeltaX != b.direction.DeltaX {
+		return a.direction.DeltaX - b.direction.DeltaX
 	}
-	return aDeltaY - bDeltaY
+	return a.direction.DeltaY - b.direction.DeltaY
 }
 
 func (c BeamCoord) ToUp() BeamCoord {
 	return BeamCoord{
-		UP, c.x, c.y - 1,
+		directions.Up, c.x, c.y - 1,
 	}
 }
 
 func (c BeamCoord) ToDown() BeamCoord {
 	return BeamCoord{
-		DOWN, c.x, c.y + 1,
+		directions.Down, c.x, c.y + 1,
 	}
 }
 
 func (c BeamCoord) ToRight() BeamCoord {
 	return BeamCoord{
-		RIGHT, c.x + 1, c.y,
+		directions.Right, c.x + 1, c.y,
 	}
 }
 
 func (c BeamCoord) ToLeft() BeamCoord {
 	return BeamCoord{
-		LEFT, c.x - 1, c.y,
+		directions.Left, c.x - 1, c.y,
 	}
 }
 
@@ -112,8 +83,7 @@ func (f BeamField) WithinField(x, y int) bool {
 }
 
 func (c BeamCoord) FlyForward(f BeamField) []BeamCoord {
-	deltaX, deltaY := DirectionToDelta(c.direction)
-	newX, newY := c.x+deltaX, c.y+deltaY
+	newX, newY := c.x+c.direction.DeltaX, c.y+c.direction.DeltaY
 	if !f.WithinField(newX, newY) {
 		return nil
 	} else {
@@ -141,44 +111,44 @@ func (c BeamCoord) Step(f BeamField) []BeamCoord {
 	case '.':
 		return c.FlyForward(f)
 	case '|':
-		switch c.direction {
-		case UP, DOWN:
+		switch c.direction.Name {
+		case common.UP, common.DOWN:
 			return c.FlyForward(f)
-		case LEFT, RIGHT:
+		case common.LEFT, common.RIGHT:
 			return FilterCorrectCoords(
 				[]BeamCoord{
 					c.ToUp(),
 					c.ToDown()}, f)
 		}
 	case '-':
-		switch c.direction {
-		case LEFT, RIGHT:
+		switch c.direction.Name {
+		case common.LEFT, common.RIGHT:
 			return c.FlyForward(f)
-		case UP, DOWN:
+		case common.UP, common.DOWN:
 			return FilterCorrectCoords([]BeamCoord{
 				c.ToLeft(),
 				c.ToRight()}, f)
 		}
 	case '\\':
-		switch c.direction {
-		case UP:
+		switch c.direction.Name {
+		case common.UP:
 			return FilterCorrectCoords([]BeamCoord{c.ToLeft()}, f)
-		case RIGHT:
+		case common.RIGHT:
 			return FilterCorrectCoords([]BeamCoord{c.ToDown()}, f)
-		case DOWN:
+		case common.DOWN:
 			return FilterCorrectCoords([]BeamCoord{c.ToRight()}, f)
-		case LEFT:
+		case common.LEFT:
 			return FilterCorrectCoords([]BeamCoord{c.ToUp()}, f)
 		}
 	case '/':
-		switch c.direction {
-		case UP:
+		switch c.direction.Name {
+		case common.UP:
 			return FilterCorrectCoords([]BeamCoord{c.ToRight()}, f)
-		case RIGHT:
+		case common.RIGHT:
 			return FilterCorrectCoords([]BeamCoord{c.ToUp()}, f)
-		case DOWN:
+		case common.DOWN:
 			return FilterCorrectCoords([]BeamCoord{c.ToLeft()}, f)
-		case LEFT:
+		case common.LEFT:
 			return FilterCorrectCoords([]BeamCoord{c.ToDown()}, f)
 		}
 	}
@@ -195,7 +165,7 @@ func HashCoords(coords []BeamCoord) uint64 {
 		hash.Write(b)
 		binary.LittleEndian.PutUint64(b, uint64(coord.y))
 		hash.Write(b)
-		hash.Write([]byte(coord.direction))
+		hash.Write([]byte(coord.direction.Name))
 	}
 	return hash.Sum64()
 }
@@ -209,7 +179,7 @@ func Draw(f BeamField, beams []BeamCoord) string {
 		}
 	}
 	for _, b := range beams {
-		chars[b.y][b.x] = DirectionNameToChar(b.direction)
+		chars[b.y][b.x] = b.direction.Char
 	}
 
 	var sb strings.Builder
@@ -223,17 +193,17 @@ func Draw(f BeamField, beams []BeamCoord) string {
 func startCoordConfigurations(f BeamField) [][]BeamCoord {
 	configs := make([][]BeamCoord, 0)
 	for x := 0; x < len(f.tiles[0]); x++ {
-		configs = append(configs, []BeamCoord{{DOWN, x, 0}})
-		configs = append(configs, []BeamCoord{{UP, x, len(f.tiles) - 1}})
+		configs = append(configs, []BeamCoord{{directions.Down, x, 0}})
+		configs = append(configs, []BeamCoord{{directions.Up, x, len(f.tiles) - 1}})
 	}
 	for y := 0; y < len(f.tiles); y++ {
-		configs = append(configs, []BeamCoord{{RIGHT, 0, y}})
-		configs = append(configs, []BeamCoord{{LEFT, len(f.tiles[0]) - 1, y}})
+		configs = append(configs, []BeamCoord{{directions.Right, 0, y}})
+		configs = append(configs, []BeamCoord{{directions.Left, len(f.tiles[0]) - 1, y}})
 	}
 	return configs
 }
 
-func findMaxEnergized(field BeamField, coords []BeamCoord, output chan<- int, wg *sync.WaitGroup) {
+func simulateEnergized(field BeamField, coords []BeamCoord) int {
 	encounteredHashes := make(map[uint64][]BeamCoord)
 	encounteredCoords := make(map[Coord]int)
 	hash := HashCoords(coords)
@@ -253,34 +223,74 @@ func findMaxEnergized(field BeamField, coords []BeamCoord, output chan<- int, wg
 		hash = HashCoords(coords)
 		_, exists = encounteredHashes[hash]
 	}
-	output <- len(encounteredCoords)
-	wg.Done()
+	return len(encounteredCoords)
+}
+
+func writeAnimation(field BeamField, start []BeamCoord) (err error) {
+	var w io.Writer = os.Stdout
+	if *animationOutput != "" {
+		f, createErr := os.Create(*animationOutput)
+		if createErr != nil {
+			return createErr
+		}
+		defer func() {
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		w = f
+	}
+	recorder := RecordPropagation(field, start)
+	switch *animation {
+	case "ansi":
+		return recorder.PlayAnsi(w, *frameDelay)
+	default:
+		return recorder.WriteGif(w, *frameDelay, GifCellSize)
+	}
 }
 
 func main() {
+	flag.Parse()
+	if *animation != "" && *animation != "gif" && *animation != "ansi" {
+		log.Fatalf("Unknown animation format %q", *animation)
+	}
+	if *animation == "gif" && *animationOutput == "" {
+		log.Fatalf("A gif animation needs --animation-output")
+	}
+	ctx, cancel := common.SolverContext(*timeout)
+	defer cancel()
+
 	//rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t16-beams/test.txt")
 	rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t16-beams/1.txt")
 	field := BeamField{tiles: lo.Map(rows, func(s string, index int) []rune {
 		return []rune(s)
 	})}
 	if err != nil {
-		log.Fatalf("Failed to open file: %w", err)
+		log.Fatalf("Failed to open file: %v", err)
 	}
 
-	maxEnergized := 0
-	var wg sync.WaitGroup
-	coordConfigurations := startCoordConfigurations(field)
-	wg.Add(len(coordConfigurations))
+	graph := NewSegmentGraph(field)
+	fmt.Printf("Built segment graph with %d splitters and %d components\n",
+		len(graph.nodes), len(graph.componentTiles))
 
-	fmt.Printf("Processing %d configurations\n", len(coordConfigurations))
-	results := make(chan int, len(coordConfigurations))
-	for _, coords := range coordConfigurations {
-		go findMaxEnergized(field, coords, results, &wg)
+	topLeft := []BeamCoord{{directions.Right, 0, 0}}
+	energized := graph.Energized(topLeft)
+	if simulated := simulateEnergized(field, topLeft); simulated != energized {
+		log.Fatalf("Segment graph disagrees with simulation: %d != %d", energized, simulated)
 	}
-	wg.Wait()
-	close(results)
-	for x := range results {
-		maxEnergized = max(maxEnergized, x)
+	fmt.Printf("Energized from top left: %d\n", energized)
+
+	if *animation != "" {
+		if err := writeAnimation(field, topLeft); err != nil {
+			log.Fatalf("Failed to write the animation: %v", err)
+		}
+	}
+
+	coordConfigurations := startCoordConfigurations(field)
+	fmt.Printf("Processing %d configurations\n", len(coordConfigurations))
+	maxEnergized, err := MaxEnergized(ctx, graph, coordConfigurations, *workers)
+	if err != nil {
+		log.Fatalf("Failed to find the max: %v", err)
 	}
 
 	fmt.Printf("Max: %d\n", maxEnergized)
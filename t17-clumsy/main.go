@@ -2,6 +2,8 @@ package main
 
 import (
 	"advent_of_code/common"
+	"context"
+	"flag"
 	"fmt"
 	"github.com/samber/lo"
 	"log"
@@ -12,6 +14,8 @@ import (
 const MAX_STRAIGHT_STEPS = 10
 const MIN_STRAIGHT_STEPS = 4
 
+var timeout = common.TimeoutFlag()
+
 type MoveState struct {
 	x, y          int
 	direction     common.DirectionDesc
@@ -158,13 +162,9 @@ func NewField(rawField []string) Field {
 	return Field{tiles: tiles}
 }
 
-func main() {
-	values, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t17-clumsy/1.txt")
-	if err != nil {
-		log.Fatalf("%w", err)
-	}
-	field := NewField(values)
-	directions := common.NewDirections()
+// FindMinLoss expands all the paths from the top left corner step by step,
+// keeping only those that improve on the known losses, until none are left.
+func FindMinLoss(ctx context.Context, field Field, directions common.Directions) (int, error) {
 	states := []MoveStateList{
 		{MoveState: MoveState{x: 0, y: 0, direction: directions.Right, sinceLastTurn: 0}, prev: nil},
 		{MoveState: MoveState{x: 0, y: 0, direction: directions.Down, sinceLastTurn: 0}, prev: nil},
@@ -174,7 +174,7 @@ func main() {
 		states[1].MoveState: {&states[1], 0},
 	}
 	minEncounteredLoss := -1
-	ctx := TaskContext{
+	task := TaskContext{
 		directions:         directions,
 		minEncounteredLoss: &minEncounteredLoss,
 		minLossByState:     &minLossByState,
@@ -182,10 +182,33 @@ func main() {
 	}
 	iterations := 0
 	for len(states) > 0 {
+		err := common.CheckInterrupted(ctx, "%d iterations with %d states left, min loss so far %d",
+			iterations, len(states), minEncounteredLoss)
+		if err != nil {
+			return minEncounteredLoss, err
+		}
 		fmt.Printf("Iterations: %d, states: %d\n", iterations, len(states))
 		states = lo.FlatMap(states, func(item MoveStateList, index int) []MoveStateList {
-			return item.NextSteps(ctx)
+			return item.NextSteps(task)
 		})
+		iterations++
+	}
+	return minEncounteredLoss, nil
+}
+
+func main() {
+	flag.Parse()
+	ctx, cancel := common.SolverContext(*timeout)
+	defer cancel()
+
+	values, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t17-clumsy/1.txt")
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	field := NewField(values)
+	minLoss, err := FindMinLoss(ctx, field, common.NewDirections())
+	if err != nil {
+		log.Fatalf("Failed to find the min loss: %v", err)
 	}
-	fmt.Printf("Min loss: %d\n", *ctx.minEncounteredLoss)
+	fmt.Printf("Min loss: %d\n", minLoss)
 }
@@ -2,6 +2,8 @@ package main
 
 import (
 	"advent_of_code/common"
+	"context"
+	"flag"
 	"fmt"
 	"github.com/emirpasic/gods/maps/treemap"
 	"github.com/samber/lo"
@@ -197,30 +199,19 @@ func (f Field) Tiles() []Tile {
 	return tiles
 }
 
-func (c TaskContext) DirectionFromChar(dir string) common.DirectionDesc {
-	switch dir {
-	case "R":
-		return c.directions.Right
-	case "L":
-		return c.directions.Left
-	case "U":
-		return c.directions.Up
-	case "D":
-		return c.directions.Down
-	}
-	log.Fatalf("Unreacheable")
-	return c.directions.Left
-}
-
 type DigStep struct {
 	direction common.DirectionDesc
 	size      int
+	color     string
 }
 
 func ParseDigStepSimple(ctx TaskContext, s string) DigStep {
 	// L 5 (#7e2d02)
 	components := strings.Fields(s)
-	direction := ctx.DirectionFromChar(components[0])
+	direction, err := ctx.directions.FromString(components[0])
+	if err != nil {
+		log.Fatalf("Failed to parse direction: %v", err)
+	}
 	size, err := strconv.Atoi(components[1])
 	if err != nil {
 		log.Fatalf("Failed to parse int")
@@ -228,6 +219,7 @@ func ParseDigStepSimple(ctx TaskContext, s string) DigStep {
 	return DigStep{
 		direction: direction,
 		size:      size,
+		color:     strings.Trim(components[2], "()"),
 	}
 }
 
@@ -237,26 +229,17 @@ func ParseDigStep(ctx TaskContext, s string) DigStep {
 	rawRgb := strings.Trim(components[2], "()")
 	var size, rawDirection int
 	_, err := fmt.Sscanf(rawRgb, "#%05x%01x", &size, &rawDirection)
-	var direction common.DirectionDesc
-	// 0 means R, 1 means D, 2 means L, and 3 means U.
-	switch rawDirection {
-	case 0:
-		direction = ctx.directions.Right
-	case 1:
-		direction = ctx.directions.Down
-	case 2:
-		direction = ctx.directions.Left
-	case 3:
-		direction = ctx.directions.Up
-	default:
-		log.Fatalf("Unreacheable")
-	}
 	if err != nil {
 		log.Fatalf("Failed to parse color")
 	}
+	direction, err := ctx.directions.FromHexCode(rawDirection)
+	if err != nil {
+		log.Fatalf("Failed to parse direction: %v", err)
+	}
 	return DigStep{
 		direction: direction,
 		size:      size,
+		color:     rawRgb,
 	}
 }
 
@@ -360,35 +343,29 @@ func (f Field) Bfs(start Tile, visited *map[Tile]struct{}, visitor func(Tile)) {
 	}
 }
 
-func main() {
-	//rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t18-lavaduct-lagoon/test.txt")
-	rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t18-lavaduct-lagoon/1.txt")
-	if err != nil {
-		log.Fatalf("Failed to read file")
-	}
-	ctx := TaskContext{
-		directions: common.NewDirections(),
-	}
-	digSteps := lo.Map(rows, common.NoIndex(func(row string) DigStep {
-		return ParseDigStep(ctx, row)
-	}))
-	field := NewField(digSteps)
-	tiles := field.Tiles()
+// LagoonArea adds up the tiles that are either on the border or enclosed by
+// it. Tiles reachable from the edge of the field without crossing the border
+// are outside.
+func (f Field) LagoonArea(ctx context.Context) (int64, error) {
+	tiles := f.Tiles()
 	tileToVisited := make(map[Tile]struct{})
 	internalTiles := make([]Tile, 0)
-	for _, tile := range tiles {
+	for i, tile := range tiles {
 		if _, already := tileToVisited[tile]; already {
 			continue
 		}
-		if field.IsBarBorder(tile) {
+		if f.IsBarBorder(tile) {
 			continue
 		}
+		if err := common.CheckInterrupted(ctx, "%d of %d tiles", i, len(tiles)); err != nil {
+			return 0, err
+		}
 
 		encounteredTiles := make([]Tile, 0)
 		isInternal := true
-		field.Bfs(tile, &tileToVisited, func(t Tile) {
+		f.Bfs(tile, &tileToVisited, func(t Tile) {
 			encounteredTiles = append(encounteredTiles, t)
-			isInternal = isInternal && !t.IsAtEdge(field)
+			isInternal = isInternal && !t.IsAtEdge(f)
 		})
 		if isInternal {
 			internalTiles = append(internalTiles, encounteredTiles...)
@@ -396,11 +373,44 @@ func main() {
 	}
 
 	borders := lo.Filter(tiles, func(t Tile, index int) bool {
-		return field.IsBarBorder(t)
+		return f.IsBarBorder(t)
 	})
 	dag := make([]Tile, 0)
 	dag = append(dag, internalTiles...)
 	dag = append(dag, borders...)
+	return lo.Sum(lo.Map(dag, common.NoIndex(Tile.Area))), nil
+}
+
+var (
+	svgPath = flag.String("svg", "", "render the dig plan as an svg to this path")
+	timeout = common.TimeoutFlag()
+)
+
+func main() {
+	flag.Parse()
+	ctx, cancel := common.SolverContext(*timeout)
+	defer cancel()
 
-	fmt.Printf("Anser: %d", lo.Sum(lo.Map(dag, common.NoIndex(Tile.Area))))
+	//rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t18-lavaduct-lagoon/test.txt")
+	rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t18-lavaduct-lagoon/1.txt")
+	if err != nil {
+		log.Fatalf("Failed to read file")
+	}
+	taskCtx := TaskContext{
+		directions: common.NewDirections(),
+	}
+	digSteps := lo.Map(rows, common.NoIndex(func(row string) DigStep {
+		return ParseDigStep(taskCtx, row)
+	}))
+	if *svgPath != "" {
+		if err := WriteSvg(*svgPath, digSteps, DefaultSvgOptions()); err != nil {
+			log.Fatalf("Failed to render the dig plan: %v", err)
+		}
+	}
+	field := NewField(digSteps)
+	area, err := field.LagoonArea(ctx)
+	if err != nil {
+		log.Fatalf("Failed to measure the lagoon: %v", err)
+	}
+	fmt.Printf("Anser: %d", area)
 }
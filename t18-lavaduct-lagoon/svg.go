@@ -0,0 +1,108 @@
+package main
+
+import (
+	"advent_of_code/common"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type SvgOptions struct {
+	// Maximum canvas size in pixels, the aspect ratio of the plan is preserved.
+	Width, Height int
+	Padding       float64
+	StrokeWidth   float64
+	InteriorFill  string
+}
+
+func DefaultSvgOptions() SvgOptions {
+	return SvgOptions{
+		Width:        1000,
+		Height:       1000,
+		Padding:      10,
+		StrokeWidth:  2,
+		InteriorFill: "#d9d9d9",
+	}
+}
+
+func DigStepsToVertices(digSteps []DigStep) []common.Coord {
+	vertices := make([]common.Coord, 0, len(digSteps)+1)
+	var at common.Coord
+	vertices = append(vertices, at)
+	for _, step := range digSteps {
+		at = at.Move(step.direction, step.size)
+		vertices = append(vertices, at)
+	}
+	return vertices
+}
+
+type svgProjection struct {
+	minX, minY int
+	scale      float64
+	padding    float64
+}
+
+func newSvgProjection(vertices []common.Coord, opts SvgOptions) svgProjection {
+	minX, maxX := vertices[0].X, vertices[0].X
+	minY, maxY := vertices[0].Y, vertices[0].Y
+	for _, v := range vertices {
+		minX, maxX = min(minX, v.X), max(maxX, v.X)
+		minY, maxY = min(minY, v.Y), max(maxY, v.Y)
+	}
+	// Coordinates are used in float64 so that part 2 plans (millions of meters)
+	// are scaled down instead of producing a gigantic viewBox.
+	drawWidth := float64(opts.Width) - 2*opts.Padding
+	drawHeight := float64(opts.Height) - 2*opts.Padding
+	spanX := float64(max(maxX-minX, 1))
+	spanY := float64(max(maxY-minY, 1))
+	return svgProjection{
+		minX:    minX,
+		minY:    minY,
+		scale:   min(drawWidth/spanX, drawHeight/spanY),
+		padding: opts.Padding,
+	}
+}
+
+func (p svgProjection) Project(c common.Coord) (float64, float64) {
+	return p.padding + float64(c.X-p.minX)*p.scale, p.padding + float64(c.Y-p.minY)*p.scale
+}
+
+func RenderSvg(digSteps []DigStep, opts SvgOptions) string {
+	vertices := DigStepsToVertices(digSteps)
+	proj := newSvgProjection(vertices, opts)
+
+	var sb strings.Builder
+	maxX, maxY := 0.0, 0.0
+	points := make([]string, 0, len(vertices))
+	for _, v := range vertices {
+		x, y := proj.Project(v)
+		maxX, maxY = max(maxX, x), max(maxY, y)
+		points = append(points, fmt.Sprintf("%.2f,%.2f", x, y))
+	}
+	width, height := maxX+opts.Padding, maxY+opts.Padding
+
+	fmt.Fprintf(&sb, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%.0f\" height=\"%.0f\" viewBox=\"0 0 %.2f %.2f\">\n",
+		width, height, width, height)
+	fmt.Fprintf(&sb, "  <polygon points=\"%s\" fill=\"%s\" stroke=\"none\"/>\n",
+		strings.Join(points, " "), opts.InteriorFill)
+	for i, step := range digSteps {
+		x0, y0 := proj.Project(vertices[i])
+		x1, y1 := proj.Project(vertices[i+1])
+		color := step.color
+		if color == "" {
+			color = "#000000"
+		}
+		fmt.Fprintf(&sb, "  <line x1=\"%.2f\" y1=\"%.2f\" x2=\"%.2f\" y2=\"%.2f\" stroke=\"%s\" stroke-width=\"%.2f\" stroke-linecap=\"square\"/>\n",
+			x0, y0, x1, y1, color, opts.StrokeWidth)
+	}
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+func WriteSvg(path string, digSteps []DigStep, opts SvgOptions) error {
+	err := os.WriteFile(path, []byte(RenderSvg(digSteps, opts)), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write svg to %s: %w", path, err)
+	}
+	return nil
+}
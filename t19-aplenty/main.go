@@ -2,11 +2,13 @@ package main
 
 import (
 	"advent_of_code/common"
+	"advent_of_code/common/parse"
+	"context"
+	"flag"
 	"fmt"
 	"github.com/dlclark/regexp2"
 	"github.com/samber/lo"
 	"log"
-	"os"
 	"strconv"
 	"strings"
 )
@@ -21,7 +23,6 @@ const (
 
 var TriggerRegex = regexp2.MustCompile("^(?<prop>\\w{1})(?<op>[<>]{1})(?<threshold>\\d+):(?<outcome>\\w+)$", regexp2.IgnoreCase)
 var TerminationTriggerRegex = regexp2.MustCompile("^\\w+$", regexp2.IgnoreCase)
-var DetailRe = regexp2.MustCompile("^{x=(?<x>\\d+),m=(?<m>\\d+),a=(?<a>\\d+),s=(?<s>\\d+)}$", regexp2.IgnoreCase)
 
 func AlwaysTrueCond(d Detail) bool {
 	return true
@@ -266,34 +267,30 @@ func ParseWorkflow(raw string) Workflow {
 }
 
 func ParseDetail(rawDetail string) Detail {
-	m, err := DetailRe.FindStringMatch(rawDetail)
-	if err != nil {
-		log.Fatalf("Failed to parse detail %s", rawDetail)
-	}
-	xRaw := m.GroupByName("x").String()
-	mRaw := m.GroupByName("m").String()
-	aRaw := m.GroupByName("a").String()
-	sRaw := m.GroupByName("s").String()
-
-	return Detail{
-		x: common.MustAtoi(xRaw),
-		m: common.MustAtoi(mRaw),
-		a: common.MustAtoi(aRaw),
-		s: common.MustAtoi(sRaw),
+	var d Detail
+	if err := parse.Sscan(rawDetail, "{{x={x},m={m},a={a},s={s}}}", &d.x, &d.m, &d.a, &d.s); err != nil {
+		log.Fatalf("Failed to parse detail: %v", err)
 	}
+	return d
 }
 
-func ParseWorkflowsAndDetails(contents string) ([]Workflow, []Detail) {
-	components := strings.Split(contents, "\n\n")
-	rawWorkflows := strings.Split(components[0], "\n")
-	rawDetails := strings.Split(components[1], "\n")
+func ParseWorkflowsAndDetails(blocks *common.Iterator[[]string]) ([]Workflow, []Detail, error) {
+	components, err := blocks.Collect()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	if len(components) != 2 {
+		return nil, nil, fmt.Errorf("expected workflows and details, got %d blocks", len(components))
+	}
+	rawWorkflows := components[0]
+	rawDetails := components[1]
 
 	workflows := lo.Map(rawWorkflows, common.NoIndex(ParseWorkflow))
 	details := lo.Map(rawDetails, common.NoIndex(ParseDetail))
-	return workflows, details
+	return workflows, details, nil
 }
 
-func ProcessDetails(details []Detail, workflows []Workflow) []Detail {
+func ProcessDetails(ctx context.Context, details []Detail, workflows []Workflow) ([]Detail, error) {
 	nameToWorkflow := lo.MapValues(lo.GroupBy(workflows, func(w Workflow) string {
 		return w.name
 	}), func(w []Workflow, key string) Workflow {
@@ -303,7 +300,11 @@ func ProcessDetails(details []Detail, workflows []Workflow) []Detail {
 		return lo.T2(d, Input)
 	})
 	acceptedDetails := make([]Detail, 0)
-	for len(activeDetails) > 0 {
+	for pass := 0; len(activeDetails) > 0; pass++ {
+		err := common.CheckInterrupted(ctx, "%d passes with %d details in progress", pass, len(activeDetails))
+		if err != nil {
+			return nil, err
+		}
 		newActiveDetails := make([]lo.Tuple2[Detail, string], 0)
 		for _, detail := range activeDetails {
 			newWorkflow := nameToWorkflow[detail.B].Process(detail.A)
@@ -317,7 +318,7 @@ func ProcessDetails(details []Detail, workflows []Workflow) []Detail {
 		}
 		activeDetails = newActiveDetails
 	}
-	return acceptedDetails
+	return acceptedDetails, nil
 }
 
 func DebugPrintActiveDetails(m []lo.Tuple2[DetailRange, string]) {
@@ -333,7 +334,7 @@ func DebugPrintActiveDetails(m []lo.Tuple2[DetailRange, string]) {
 	fmt.Printf("---\n")
 }
 
-func ProcessDetailRange(detailRanges []DetailRange, workflows []Workflow) []DetailRange {
+func ProcessDetailRange(ctx context.Context, detailRanges []DetailRange, workflows []Workflow) ([]DetailRange, error) {
 	nameToWorkflow := lo.MapValues(lo.GroupBy(workflows, func(w Workflow) string {
 		return w.name
 	}), func(w []Workflow, key string) Workflow {
@@ -343,7 +344,11 @@ func ProcessDetailRange(detailRanges []DetailRange, workflows []Workflow) []Deta
 		return lo.T2(d, Input)
 	})
 	acceptedDetails := make([]DetailRange, 0)
-	for len(activeDetails) > 0 {
+	for pass := 0; len(activeDetails) > 0; pass++ {
+		err := common.CheckInterrupted(ctx, "%d passes with %d ranges in progress", pass, len(activeDetails))
+		if err != nil {
+			return nil, err
+		}
 		newActiveDetails := make([]lo.Tuple2[DetailRange, string], 0)
 		for _, detail := range activeDetails {
 			newRangesAndWorkflows := nameToWorkflow[detail.B].ProcessRange(detail.A)
@@ -361,16 +366,24 @@ func ProcessDetailRange(detailRanges []DetailRange, workflows []Workflow) []Deta
 		}
 		activeDetails = newActiveDetails
 	}
-	return acceptedDetails
-
+	return acceptedDetails, nil
 }
 
+var timeout = common.TimeoutFlag()
+
 func main() {
-	file, err := os.ReadFile("/Users/iv/Code/advent-of-code-2023/t19-aplenty/1.txt")
+	flag.Parse()
+	ctx, cancel := common.SolverContext(*timeout)
+	defer cancel()
+
+	blocks, err := common.OpenBlocks("/Users/iv/Code/advent-of-code-2023/t19-aplenty/1.txt")
 	if err != nil {
-		log.Fatalf("Failed to read file: %w", err)
+		log.Fatalf("Failed to read file: %v", err)
+	}
+	workflows, _, err := ParseWorkflowsAndDetails(blocks)
+	if err != nil {
+		log.Fatalf("Failed to parse input: %v", err)
 	}
-	workflows, _ := ParseWorkflowsAndDetails(string(file))
 
 	//detailRanges := lo.Map(details, func(item Detail, index int) DetailRange {
 	//	return item.ToRange()
@@ -387,7 +400,10 @@ func main() {
 		sMax: MAX_RANGE_EXCLUDED,
 	}}
 
-	acceptedDetails := ProcessDetailRange(detailRanges, workflows)
+	acceptedDetails, err := ProcessDetailRange(ctx, detailRanges, workflows)
+	if err != nil {
+		log.Fatalf("Failed to process the ranges: %v", err)
+	}
 	fmt.Printf("Sum: %d\n", lo.SumBy(acceptedDetails, func(d DetailRange) int {
 		return d.Size()
 	}))
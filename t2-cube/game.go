@@ -0,0 +1,164 @@
+package main
+
+import (
+	"advent_of_code/common/parse"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var StandardColors = []string{"red", "green", "blue"}
+
+// Inventory counts cubes by colour, missing colours have no cubes.
+type Inventory map[string]int
+
+func (i Inventory) Power() int64 {
+	power := int64(1)
+	for _, count := range i {
+		power *= int64(count)
+	}
+	return power
+}
+
+// Contains tells whether every colour of other fits into the inventory.
+func (i Inventory) Contains(other Inventory) bool {
+	for color, count := range other {
+		if count > i[color] {
+			return false
+		}
+	}
+	return true
+}
+
+// Minus takes the cubes of delta out of the inventory, never going below 0.
+func (i Inventory) Minus(delta Inventory) Inventory {
+	result := make(Inventory, len(i))
+	for color, count := range i {
+		result[color] = max(0, count-delta[color])
+	}
+	return result
+}
+
+func (i Inventory) String() string {
+	colors := make([]string, 0, len(i))
+	for color := range i {
+		colors = append(colors, color)
+	}
+	slices.Sort(colors)
+	parts := make([]string, 0, len(colors))
+	for _, color := range colors {
+		parts = append(parts, fmt.Sprintf("%d %s", i[color], color))
+	}
+	return strings.Join(parts, ", ")
+}
+
+type Game struct {
+	GameIndex int
+	// Rounds have all the colours of the game, shown or not.
+	Rounds []Inventory
+}
+
+func (g *Game) PossibleForInventory(i Inventory) bool {
+	return i.Contains(g.MinimumInventory())
+}
+
+func (g *Game) MinimumInventory() Inventory {
+	minimum := make(Inventory)
+	for _, round := range g.Rounds {
+		for color, count := range round {
+			minimum[color] = max(minimum[color], count)
+		}
+	}
+	return minimum
+}
+
+func parseGameRound(rawRound string, colors []string) (Inventory, error) {
+	if strings.TrimSpace(rawRound) == "" {
+		return nil, fmt.Errorf("empty round")
+	}
+	round := make(Inventory, len(colors))
+	for _, color := range colors {
+		round[color] = 0
+	}
+	seen := make(map[string]bool)
+	for _, rawColorCount := range strings.Split(rawRound, ",") {
+		var count int
+		var color string
+		if err := parse.Sscan(rawColorCount, "{count} {color}", &count, &color); err != nil {
+			return nil, fmt.Errorf("malformed cubes %q: %w", strings.TrimSpace(rawColorCount), err)
+		}
+		if !slices.Contains(colors, color) {
+			return nil, fmt.Errorf("unknown color %q", color)
+		}
+		if seen[color] {
+			return nil, fmt.Errorf("color %s is shown twice in %q", color, strings.TrimSpace(rawRound))
+		}
+		if count < 0 {
+			return nil, fmt.Errorf("negative count of %s", color)
+		}
+		seen[color] = true
+		round[color] = count
+	}
+	return round, nil
+}
+
+// ParseGame reads a line like
+// "Game 1: 3 blue, 4 red; 1 red, 2 green, 6 blue; 2 green". Colours other than
+// the given ones are an error.
+func ParseGame(line string, colors []string) (Game, error) {
+	var g Game
+	var rawRounds []string
+	if err := parse.Sscan(line, "Game {index}: {rounds...;}", &g.GameIndex, &rawRounds); err != nil {
+		return Game{}, err
+	}
+	if len(rawRounds) == 0 {
+		return Game{}, fmt.Errorf("game %d has no rounds", g.GameIndex)
+	}
+	for i, rawRound := range rawRounds {
+		round, err := parseGameRound(rawRound, colors)
+		if err != nil {
+			return Game{}, fmt.Errorf("game %d, round %d: %w", g.GameIndex, i+1, err)
+		}
+		g.Rounds = append(g.Rounds, round)
+	}
+	return g, nil
+}
+
+func PossibleGames(games []Game, i Inventory) []Game {
+	var possible []Game
+	for _, g := range games {
+		if g.PossibleForInventory(i) {
+			possible = append(possible, g)
+		}
+	}
+	return possible
+}
+
+// BrokenByReduction lists the games possible with the inventory that become
+// impossible once delta is taken out of it.
+func BrokenByReduction(games []Game, i Inventory, delta Inventory) []Game {
+	reduced := i.Minus(delta)
+	var broken []Game
+	for _, g := range PossibleGames(games, i) {
+		if !g.PossibleForInventory(reduced) {
+			broken = append(broken, g)
+		}
+	}
+	return broken
+}
+
+// Headroom tells for every colour of the inventory how many cubes can be
+// taken away before any of the currently possible games becomes impossible.
+func Headroom(games []Game, i Inventory) Inventory {
+	needed := make(Inventory)
+	for _, g := range PossibleGames(games, i) {
+		for color, count := range g.MinimumInventory() {
+			needed[color] = max(needed[color], count)
+		}
+	}
+	headroom := make(Inventory, len(i))
+	for color, count := range i {
+		headroom[color] = count - needed[color]
+	}
+	return headroom
+}
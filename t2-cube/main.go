@@ -2,116 +2,41 @@ package main
 
 import (
 	"advent_of_code/common"
+	"flag"
 	"fmt"
 	"github.com/samber/lo"
 	"log"
-	"strings"
 )
 
-type GameRound struct {
-	Blue  int
-	Red   int
-	Green int
-}
-
-type Game struct {
-	GameIndex int
-	Rounds    []GameRound
-}
-
-type Inventory struct {
-	Blue  int
-	Red   int
-	Green int
-}
-
-func (i Inventory) Power() int64 {
-	return int64(i.Red) * int64(i.Green) * int64(i.Blue)
-}
-
-func (g *Game) PossibleForInventory(i Inventory) bool {
-	return lo.EveryBy(g.Rounds, func(r GameRound) bool {
-		return r.Red <= i.Red && r.Blue <= i.Blue && r.Green <= i.Green
-	})
-}
-
-func (g *Game) MinimumInventory() Inventory {
-	return Inventory{
-		Red: lo.Max(lo.Map(g.Rounds, func(r GameRound, index int) int {
-			return r.Red
-		})),
-		Green: lo.Max(lo.Map(g.Rounds, func(r GameRound, index int) int {
-			return r.Green
-		})),
-		Blue: lo.Max(lo.Map(g.Rounds, func(r GameRound, index int) int {
-			return r.Blue
-		})),
+func ReadGames(path string) ([]Game, error) {
+	rows, err := common.FileToRows(path)
+	if err != nil {
+		return nil, err
 	}
-}
-
-func parseGameRound(rawRound string) GameRound {
-	rawColorCounts := strings.Split(rawRound, ",")
-
-	var blue, green, red, count int
-	var color string
-	for _, rawColorCount := range rawColorCounts {
-		_, err := fmt.Sscanf(rawColorCount, "%d %s", &count, &color)
+	games := make([]Game, 0, len(rows))
+	for i, row := range rows {
+		g, err := ParseGame(row, StandardColors)
 		if err != nil {
-			log.Fatalf("Failed to parse color: %s, %w", rawColorCount, err)
-		}
-
-		switch color {
-		case "red":
-			red = count
-		case "green":
-			green = count
-		case "blue":
-			blue = count
-		default:
-			log.Fatalf("Invalid color parsed: %s", color)
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
 		}
+		games = append(games, g)
 	}
-	return GameRound{
-		Blue:  blue,
-		Red:   red,
-		Green: green,
-	}
+	return games, nil
 }
 
-func GameFromLine(line string) Game {
-	// Example of input:
-	// Game 1: 3 blue, 4 red; 1 red, 2 green, 6 blue; 2 green
-	var gameIndex int
-	_, err := fmt.Sscanf(line, "Game %d:", &gameIndex)
-	if err != nil {
-		log.Fatalf("Failed to parse game: %s %w", line, err)
-	}
-	rest := strings.TrimSpace(strings.Split(line, ":")[1])
-	rawRounds := strings.Split(rest, ";")
-	rounds := lo.Map(rawRounds, common.NoIndex(parseGameRound))
-	return Game{
-		GameIndex: gameIndex,
-		Rounds:    rounds,
-	}
-}
+var partOneInventory = Inventory{"red": 12, "green": 13, "blue": 14}
+
+var reduce = flag.String("reduce", "", "cubes taken out of the part 1 inventory, like \"1 red, 2 blue\"")
 
 // Part 1
 
 //func main() {
-//	//rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t2-cube/test.txt")
-//	rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t2-cube/1.txt")
-//	inventory := Inventory{
-//		Red:   12,
-//		Green: 13,
-//		Blue:  14,
-//	}
+//	//games, err := ReadGames("/Users/iv/Code/advent-of-code-2023/t2-cube/test.txt")
+//	games, err := ReadGames("/Users/iv/Code/advent-of-code-2023/t2-cube/1.txt")
 //	if err != nil {
-//		log.Fatalf("%w", err)
+//		log.Fatalf("Failed to read games: %v", err)
 //	}
-//	games := lo.Map(rows, common.NoIndex(GameFromLine))
-//	possibleGames := lo.Filter(games, func(item Game, index int) bool {
-//		return item.PossibleForInventory(inventory)
-//	})
+//	possibleGames := PossibleGames(games, partOneInventory)
 //	fmt.Printf("%d", lo.SumBy(possibleGames, func(g Game) int {
 //		return g.GameIndex
 //	}))
@@ -119,16 +44,30 @@ func GameFromLine(line string) Game {
 
 // Part 2
 func main() {
-	rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t2-cube/1.txt")
-	//rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t2-cube/test.txt")
+	flag.Parse()
+	var delta Inventory
+	if *reduce != "" {
+		var err error
+		if delta, err = parseGameRound(*reduce, StandardColors); err != nil {
+			log.Fatalf("Invalid reduction %q: %v", *reduce, err)
+		}
+	}
+	games, err := ReadGames("/Users/iv/Code/advent-of-code-2023/t2-cube/1.txt")
+	//games, err := ReadGames("/Users/iv/Code/advent-of-code-2023/t2-cube/test.txt")
 	if err != nil {
-		log.Fatalf("%w", err)
+		log.Fatalf("Failed to read games: %v", err)
 	}
-	games := lo.Map(rows, common.NoIndex(GameFromLine))
 	powers := lo.Map(games, func(g Game, index int) int64 {
 		return g.MinimumInventory().Power()
 	})
 	println(lo.Reduce(powers, func(agg int64, item int64, index int) int64 {
 		return agg + item
 	}, int64(0)))
+
+	// What if there were fewer cubes than in part 1.
+	if delta != nil {
+		fmt.Printf("Headroom: %s\n", Headroom(games, partOneInventory))
+		broken := BrokenByReduction(games, partOneInventory, delta)
+		fmt.Printf("Taking out %s breaks %d games\n", delta, len(broken))
+	}
 }
@@ -2,6 +2,8 @@ package main
 
 import (
 	"advent_of_code/common"
+	"context"
+	"flag"
 	"fmt"
 	"github.com/samber/lo"
 	"github.com/zyedidia/generic/queue"
@@ -19,6 +21,8 @@ const (
 	PulseCount       = 1000
 )
 
+var timeout = common.TimeoutFlag()
+
 type Pulse struct {
 	strength    int
 	source      string
@@ -169,14 +173,13 @@ func Push(nameToModule *map[string]Module, elapsed int) bool {
 
 		if pulse.destination == "rx" && pulse.strength == Low {
 			return true
-			lows += 1
 		}
 		if pulse.destination == "rx" && pulse.strength == High {
 			highs += 1
 		}
 		if pulse.destination == "hf" && pulse.strength == High {
 			m := (*nameToModule)["hf"]
-			fmt.Printf("Here! &+v\n", m)
+			fmt.Printf("Here! %+v\n", m)
 		}
 
 		destination := (*nameToModule)[pulse.destination]
@@ -188,19 +191,35 @@ func Push(nameToModule *map[string]Module, elapsed int) bool {
 	return lows == 1 && highs == 0
 }
 
+// PressUntilRx pushes the button until rx gets a low pulse and returns the
+// number of presses.
+func PressUntilRx(ctx context.Context, nameToModule map[string]Module) (int, error) {
+	presses := 0
+	for {
+		if err := common.CheckInterrupted(ctx, "%d button presses", presses); err != nil {
+			return presses, err
+		}
+		presses += 1
+		if Push(&nameToModule, presses) {
+			return presses, nil
+		}
+	}
+}
+
 func main() {
+	flag.Parse()
+	ctx, cancel := common.SolverContext(*timeout)
+	defer cancel()
+
 	contents, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t20-pulse/1.txt")
 	if err != nil {
-		log.Fatalf("failed to read file: %w", err)
+		log.Fatalf("failed to read file: %v", err)
 	}
 
 	nameToModule := ParseConnections(contents)
-	var total int
-	for {
-		total += 1
-		if Push(&nameToModule, total) {
-			break
-		}
+	total, err := PressUntilRx(ctx, nameToModule)
+	if err != nil {
+		log.Fatalf("Failed to get a low pulse to rx: %v", err)
 	}
 	fmt.Printf("Total: %d\n", total)
 
@@ -2,6 +2,9 @@ package main
 
 import (
 	"advent_of_code/common"
+	"advent_of_code/common/numtheory"
+	"context"
+	"flag"
 	"fmt"
 	"github.com/samber/lo"
 	"log"
@@ -14,6 +17,8 @@ const (
 	Steps = 26501365
 )
 
+var timeout = common.TimeoutFlag()
+
 type FlipCount struct {
 	Vertical   int
 	Horizontal int
@@ -150,43 +155,43 @@ func ComputeTotal(m map[common.Coord]CoordState) int64 {
 	return total
 }
 
+// ReachableAfter counts the plots reachable in exactly steps steps. The number
+// grows quadratically with the number of field sizes walked, so it is fitted
+// with a polynomial through three samples taken a field size apart.
+func (f Field) ReachableAfter(ctx context.Context, steps int64) (int64, error) {
+	set := make(map[common.Coord]CoordState)
+	set[common.Coord{X: f.startX, Y: f.startY}] = CoordState{x: f.startX, y: f.startY,
+		flipCounts: map[FlipCount]int{FlipCount{Vertical: 0, Horizontal: 0}: 1}}
+	cycleSize := len(f.tiles)
+	var cycles, totals []int64
+	for i := 0; len(cycles) < 3; i++ {
+		if err := common.CheckInterrupted(ctx, "%d steps, %d of 3 samples", i, len(cycles)); err != nil {
+			return 0, err
+		}
+		set = f.FrontStep(set)
+		if int64(i+1)%int64(cycleSize) == steps%int64(cycleSize) {
+			fmt.Printf("%d cycles, steps %d: %d\n", (i+1)/cycleSize, i, ComputeTotal(set))
+			cycles = append(cycles, int64((i+1)/cycleSize))
+			totals = append(totals, ComputeTotal(set))
+		}
+	}
+	return numtheory.LagrangeInterpolate(cycles, totals, steps/int64(cycleSize))
+}
+
 func main() {
+	flag.Parse()
+	ctx, cancel := common.SolverContext(*timeout)
+	defer cancel()
+
 	rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t21-step-counter/1.txt")
 	if err != nil {
 		log.Fatalf("Failed to read file")
 	}
 	field := ParseField(rows)
-	set := make(map[common.Coord]CoordState)
-	set[common.Coord{X: field.startX, Y: field.startY}] = CoordState{x: field.startX, y: field.startY,
-		flipCounts: map[FlipCount]int{FlipCount{Vertical: 0, Horizontal: 0}: 1}}
 	fmt.Printf("%d %d\n", len(field.tiles[0]), len(field.tiles))
-	for i := 0; i < Steps; i++ {
-		set = field.FrontStep(set)
-		if (i+1)%131 == 65 {
-			fmt.Printf("%d cycles, steps %d: %d\n", (i+1)/131, i, ComputeTotal(set))
-		}
+	total, err := field.ReachableAfter(ctx, Steps)
+	if err != nil {
+		log.Fatalf("Failed to count reachable plots: %v", err)
 	}
-	//fmt.Printf("Total: %d\n", ComputeTotal(set))
+	fmt.Printf("Total: %d\n", total)
 }
-
-// Observe and run some python:
-
-//a = 121888
-//b = 91702
-//k=3
-//
-//i = 6
-//total = 644659
-//CYCLE_SIZE = 131
-//MAX_STEPS = 26501365
-//steps = (131*i + 65) - 1
-//
-//print(f"{steps=} {i=} {total}")
-//while True:
-//i += 2
-//steps = (131*i + 65) - 1
-//total += k*a + b
-//k += 1
-//print(f"{steps=} {i=} {total}")
-//if steps >= MAX_STEPS:
-//break
@@ -2,6 +2,9 @@ package main
 
 import (
 	"advent_of_code/common"
+	"advent_of_code/common/parse"
+	"context"
+	"flag"
 	"fmt"
 	"github.com/samber/lo"
 	"github.com/zyedidia/generic/queue"
@@ -87,9 +90,11 @@ func (f Field) Fall(r *Rect) {
 
 func ParseBrick(s string) Brick {
 	// 2,1,6
-	rawCoords := strings.Split(s, ",")
-	coords := lo.Map(rawCoords, common.NoIndex(common.MustAtoi))
-	return Brick{coords[0], coords[1], coords[2]}
+	var brick Brick
+	if err := parse.Sscan(s, "{x},{y},{z}", &brick); err != nil {
+		log.Fatalf("Failed to parse brick: %v", err)
+	}
+	return brick
 }
 
 func ParseRect(s string) Rect {
@@ -211,19 +216,17 @@ func (f Field) ComputeFallenBricks(rect *Rect) int {
 	return total
 }
 
-func main() {
-	rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t22-sand-slabs/1.txt")
-	if err != nil {
-		log.Fatalf("failed to read file: %w", err)
-	}
-	rects := lo.Map(rows, common.NoIndex(ParseRect))
-	field := BuildField(rects)
-	field.FallAll()
-
-	supportsMap, _, supportedBySingle := field.BuildSupportIndexes()
+// TotalFallenBricks adds up, for every brick that is the only support of
+// another one, how many bricks fall once it is disintegrated.
+func (f Field) TotalFallenBricks(ctx context.Context) (int, error) {
+	supportsMap, _, supportedBySingle := f.BuildSupportIndexes()
 
 	total := 0
-	for _, rect := range field.RectPts() {
+	rects := f.RectPts()
+	for i, rect := range rects {
+		if err := common.CheckInterrupted(ctx, "%d of %d bricks", i, len(rects)); err != nil {
+			return 0, err
+		}
 		rectSupports := supportsMap[rect]
 		willFall := make([]*Rect, 0)
 		for supports, _ := range rectSupports {
@@ -234,8 +237,29 @@ func main() {
 		if len(willFall) == 0 {
 			continue
 		}
-		total += field.ComputeFallenBricks(rect)
+		total += f.ComputeFallenBricks(rect)
 	}
+	return total, nil
+}
+
+var timeout = common.TimeoutFlag()
 
+func main() {
+	flag.Parse()
+	ctx, cancel := common.SolverContext(*timeout)
+	defer cancel()
+
+	rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t22-sand-slabs/1.txt")
+	if err != nil {
+		log.Fatalf("failed to read file: %v", err)
+	}
+	rects := lo.Map(rows, common.NoIndex(ParseRect))
+	field := BuildField(rects)
+	field.FallAll()
+
+	total, err := field.TotalFallenBricks(ctx)
+	if err != nil {
+		log.Fatalf("failed to count fallen bricks: %v", err)
+	}
 	fmt.Printf("Will fall: %d", total)
 }
@@ -2,6 +2,9 @@ package main
 
 import (
 	"advent_of_code/common"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"github.com/samber/lo"
 	"github.com/zyedidia/generic/stack"
@@ -16,6 +19,8 @@ const (
 	ReplaceSlopes = false
 )
 
+var timeout = common.TimeoutFlag()
+
 type Field struct {
 	startX, startY, endX, endY int
 	tiles                      [][]rune
@@ -53,9 +58,7 @@ func (f Field) CanGoTo(at common.Coord, visited map[common.Coord]int) []common.C
 	var canGoTo []common.Coord
 	if f.directions.IsSlope(runeAt) {
 		direction := f.directions.SlopeToDirection(runeAt)
-		canGoTo = []common.Coord{
-			{at.X + direction.DeltaX, at.Y + direction.DeltaY},
-		}
+		canGoTo = []common.Coord{at.Move(direction, 1)}
 	} else {
 		canGoTo = common.AdjacentCoords(at, f.tiles)
 	}
@@ -110,10 +113,13 @@ func (f Field) DebugPrintStack(s stack.Stack[*Step]) {
 	}
 }
 
-func (f Field) ExploreLongestPaths() int {
+// ExploreLongestPaths returns the number of tiles on the longest path. When
+// interrupted, it returns the longest path found so far along with the error.
+func (f Field) ExploreLongestPaths(ctx context.Context) (int, error) {
 	maxLength := 0
+	iterations := 0
 
-	start := common.Coord{f.startX, f.startY}
+	start := common.Coord{X: f.startX, Y: f.startY}
 	s := stack.New[*Step]()
 	visited := make(map[common.Coord]int)
 	visited[start] = 0
@@ -122,6 +128,13 @@ func (f Field) ExploreLongestPaths() int {
 	skipLastMulti := false
 
 	for s.Size() > 0 {
+		iterations++
+		if iterations%4096 == 0 {
+			err := common.CheckInterrupted(ctx, "%d steps, longest path so far %d", iterations, maxLength-1)
+			if err != nil {
+				return maxLength, err
+			}
+		}
 		curr := s.Peek()
 		if curr.X == f.endX && curr.Y == f.endY {
 			if s.Size() > maxLength {
@@ -156,16 +169,28 @@ func (f Field) ExploreLongestPaths() int {
 			goTo:           f.CanGoTo(curr.goTo[curr.currentlyInIdx], visited),
 		})
 	}
-	return maxLength
+	return maxLength, nil
 }
 
 func main() {
+	flag.Parse()
+	ctx, cancel := common.SolverContext(*timeout)
+	defer cancel()
+
 	rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t23-long-walk/1.txt")
 	if err != nil {
-		log.Fatalf("%w", err)
+		log.Fatalf("%v", err)
 	}
 
 	directions := common.NewDirections()
 	field := ParseField(rows, directions)
-	fmt.Printf("Max len: %d\n", field.ExploreLongestPaths()-1)
+	maxLength, err := field.ExploreLongestPaths(ctx)
+	var interrupted *common.Interrupted
+	if errors.As(err, &interrupted) {
+		fmt.Printf("Max len so far: %d\n", maxLength-1)
+	}
+	if err != nil {
+		log.Fatalf("Failed to explore paths: %v", err)
+	}
+	fmt.Printf("Max len: %d\n", maxLength-1)
 }
@@ -2,13 +2,15 @@ package main
 
 import (
 	"advent_of_code/common"
+	"advent_of_code/common/parse"
+	"context"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"github.com/samber/lo"
 	"log"
 	"os"
-	"strings"
 )
 
 var ErrNoIntersection = errors.New("no intersection")
@@ -61,32 +63,54 @@ func (s Stone) InFuture(c Coord) bool {
 	return Sign(c.x-float64(s.x)) == Sign(float64(s.vx))
 }
 
-var Replacer = strings.NewReplacer(",", "", "@", "")
-
 func ParseStone(s string) Stone {
 	// 343240821178976, 142303638369464, 376763854620819 @ -104, 127, -12
-	s = Replacer.Replace(s)
-	fields := strings.Fields(s)
-	stone := Stone{
-		x:  int64(common.MustAtoi(fields[0])),
-		y:  int64(common.MustAtoi(fields[1])),
-		z:  int64(common.MustAtoi(fields[2])),
-		vx: int64(common.MustAtoi(fields[3])),
-		vy: int64(common.MustAtoi(fields[4])),
-		vz: int64(common.MustAtoi(fields[5])),
+	var stone Stone
+	err := parse.Sscan(s, "{x}, {y}, {z} @ {vx}, {vy}, {vz}",
+		&stone.x, &stone.y, &stone.z, &stone.vx, &stone.vy, &stone.vz)
+	if err != nil {
+		log.Fatalf("Failed to parse stone: %v", err)
 	}
 	return stone
 }
 
+// CountFutureIntersections counts the pairs of stones whose paths cross
+// within the bounds, ahead of both of them.
+func CountFutureIntersections(ctx context.Context, stones []Stone) (int, error) {
+	total := 0
+	for i := 0; i < len(stones); i++ {
+		if err := common.CheckInterrupted(ctx, "%d of %d stones", i, len(stones)); err != nil {
+			return 0, err
+		}
+		for j := i + 1; j < len(stones); j++ {
+			coord, err := stones[i].IntersectsWithXY(stones[j])
+			if errors.Is(err, ErrNoIntersection) {
+				continue
+			}
+			if coord.WithinBoundsXY() && stones[i].InFuture(coord) && stones[j].InFuture(coord) {
+				//actualSet[lo.T2(i, j)] = struct{}{}
+				total += 1
+			}
+		}
+	}
+	return total, nil
+}
+
+var timeout = common.TimeoutFlag()
+
 func main() {
+	flag.Parse()
+	ctx, cancel := common.SolverContext(*timeout)
+	defer cancel()
+
 	rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t24-never-tell-me-the-odds/1.txt")
 
 	if err != nil {
-		log.Fatalf("%w", err)
+		log.Fatalf("%v", err)
 	}
 	correctRaw, err := os.ReadFile("/Users/iv/Code/advent-of-code-2023/t24-never-tell-me-the-odds/correct.json")
 	if err != nil {
-		log.Fatalf("%w", err)
+		log.Fatalf("%v", err)
 	}
 
 	var correct [][]int
@@ -100,7 +124,6 @@ func main() {
 	stones := lo.Map(rows, func(s string, index int) Stone {
 		return ParseStone(s)
 	})
-	total := 0
 
 	//{A:49 B:242}, false
 
@@ -110,17 +133,9 @@ func main() {
 	//fmt.Printf("%+v %w", coord, err)
 	//fmt.Printf("Total rows %d\n", len(rows))
 
-	for i := 0; i < len(stones); i++ {
-		for j := i + 1; j < len(stones); j++ {
-			coord, err := stones[i].IntersectsWithXY(stones[j])
-			if errors.Is(err, ErrNoIntersection) {
-				continue
-			}
-			if coord.WithinBoundsXY() && stones[i].InFuture(coord) && stones[j].InFuture(coord) {
-				//actualSet[lo.T2(i, j)] = struct{}{}
-				total += 1
-			}
-		}
+	total, err := CountFutureIntersections(ctx, stones)
+	if err != nil {
+		log.Fatalf("Failed to intersect stones: %v", err)
 	}
 
 	fmt.Printf("Total: %d\n", total)
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"advent_of_code/common"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	GEAR  = '*'
+	EMPTY = '.'
+)
+
+type Number struct {
+	X      int
+	Y      int
+	Length int
+	Value  int
+}
+
+type Symbol struct {
+	common.Coord
+	Rune rune
+}
+
+// Schematic indexes the numbers and symbols of the engine once, so that
+// adjacency queries do not rescan the rows.
+type Schematic struct {
+	Rows    []string
+	Numbers []Number
+	Symbols []Symbol
+	// numberAt holds for every cell the index of the number covering it, or -1.
+	numberAt [][]int
+}
+
+func NewSchematic(rows []string) (*Schematic, error) {
+	s := &Schematic{Rows: rows, numberAt: make([][]int, len(rows))}
+	for y, row := range rows {
+		if len(row) != len(rows[0]) {
+			return nil, fmt.Errorf("row %d has %d cells instead of %d", y+1, len(row), len(rows[0]))
+		}
+		s.numberAt[y] = make([]int, len(row))
+		for x := 0; x < len(row); x++ {
+			s.numberAt[y][x] = -1
+		}
+		for x := 0; x < len(row); x++ {
+			c := rune(row[x])
+			switch {
+			case unicode.IsDigit(c):
+				n := Number{X: x, Y: y}
+				for ; x < len(row) && unicode.IsDigit(rune(row[x])); x++ {
+					n.Value = n.Value*10 + int(row[x]-'0')
+					n.Length++
+					s.numberAt[y][x] = len(s.Numbers)
+				}
+				s.Numbers = append(s.Numbers, n)
+				x--
+			case c != EMPTY:
+				s.Symbols = append(s.Symbols, Symbol{common.Coord{X: x, Y: y}, c})
+			}
+		}
+	}
+	return s, nil
+}
+
+func (s *Schematic) LenX() int {
+	return len(s.Rows[0])
+}
+
+func (s *Schematic) LenY() int {
+	return len(s.Rows)
+}
+
+func (s *Schematic) IsValidCoord(x, y int) bool {
+	return 0 <= x && x < s.LenX() && 0 <= y && y < s.LenY()
+}
+
+func (s *Schematic) NumberAt(x, y int) (Number, bool) {
+	if !s.IsValidCoord(x, y) || s.numberAt[y][x] == -1 {
+		return Number{}, false
+	}
+	return s.Numbers[s.numberAt[y][x]], true
+}
+
+func (s *Schematic) adjacentNumberIndices(x, y int) []int {
+	var indices []int
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			if !s.IsValidCoord(x+dx, y+dy) {
+				continue
+			}
+			index := s.numberAt[y+dy][x+dx]
+			// A number can touch the cell with several of its digits, the ones
+			// of the same row are next to each other.
+			if index == -1 || dx > -1 && s.IsValidCoord(x+dx-1, y+dy) && s.numberAt[y+dy][x+dx-1] == index {
+				continue
+			}
+			indices = append(indices, index)
+		}
+	}
+	return indices
+}
+
+// AdjacentNumbers lists the numbers touching the cell, diagonally included,
+// each of them once.
+func (s *Schematic) AdjacentNumbers(x, y int) []Number {
+	indices := s.adjacentNumberIndices(x, y)
+	numbers := make([]Number, 0, len(indices))
+	for _, index := range indices {
+		numbers = append(numbers, s.Numbers[index])
+	}
+	return numbers
+}
+
+// PartNumbers are the numbers adjacent to any symbol. A number touching
+// several symbols is only listed once.
+func (s *Schematic) PartNumbers() []Number {
+	isPart := make([]bool, len(s.Numbers))
+	for _, symbol := range s.Symbols {
+		for _, index := range s.adjacentNumberIndices(symbol.X, symbol.Y) {
+			isPart[index] = true
+		}
+	}
+	var parts []Number
+	for i, n := range s.Numbers {
+		if isPart[i] {
+			parts = append(parts, n)
+		}
+	}
+	return parts
+}
+
+// SymbolsWithAdjacent lists the symbols out of the given set, or any symbol
+// for an empty set, that touch exactly count numbers.
+func (s *Schematic) SymbolsWithAdjacent(symbols string, count int) []Symbol {
+	var result []Symbol
+	for _, symbol := range s.Symbols {
+		if symbols != "" && !strings.ContainsRune(symbols, symbol.Rune) {
+			continue
+		}
+		if len(s.adjacentNumberIndices(symbol.X, symbol.Y)) == count {
+			result = append(result, symbol)
+		}
+	}
+	return result
+}
+
+// GearRatios multiplies the two numbers around every symbol of the set that
+// touches exactly two of them.
+func (s *Schematic) GearRatios(symbols string) []int64 {
+	var ratios []int64
+	for _, symbol := range s.SymbolsWithAdjacent(symbols, 2) {
+		numbers := s.AdjacentNumbers(symbol.X, symbol.Y)
+		ratios = append(ratios, int64(numbers[0].Value)*int64(numbers[1].Value))
+	}
+	return ratios
+}
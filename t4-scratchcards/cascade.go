@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrCopiesPastEnd = errors.New("card wins copies past the end of the table")
+
+type CardCopies struct {
+	Index   int
+	Matches int
+	Copies  int
+}
+
+type Cascade struct {
+	// Cards are ordered by index.
+	Cards []CardCopies
+	Total int
+}
+
+// ValidateTable checks that the cards are numbered 1, 2, 3 and so on in this
+// order, which the copies rely on.
+func ValidateTable(cards []Card) error {
+	for i, c := range cards {
+		if c.Index != i+1 {
+			return fmt.Errorf("card %d is at position %d of the table", c.Index, i+1)
+		}
+	}
+	return nil
+}
+
+// RunCascade wins copies of the following cards for every match, the
+// matches being given in the order of the cards. Every card is processed
+// before any of the cards it wins copies of, so its count is final by then.
+func RunCascade(cards []Card, matches []int) (Cascade, error) {
+	if len(cards) != len(matches) {
+		return Cascade{}, fmt.Errorf("got matches for %d out of %d cards", len(matches), len(cards))
+	}
+	if err := ValidateTable(cards); err != nil {
+		return Cascade{}, err
+	}
+
+	cascade := Cascade{Cards: make([]CardCopies, len(cards))}
+	for i, c := range cards {
+		cascade.Cards[i] = CardCopies{Index: c.Index, Matches: matches[i], Copies: 1}
+	}
+	for i, c := range cascade.Cards {
+		if i+c.Matches >= len(cards) {
+			return Cascade{}, fmt.Errorf("card %d with %d matches, the last card is %d: %w",
+				c.Index, c.Matches, len(cards), ErrCopiesPastEnd)
+		}
+		for j := 1; j <= c.Matches; j++ {
+			cascade.Cards[i+j].Copies += c.Copies
+		}
+		cascade.Total += c.Copies
+	}
+	return cascade, nil
+}
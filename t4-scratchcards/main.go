@@ -2,67 +2,56 @@ package main
 
 import (
 	"advent_of_code/common"
+	"advent_of_code/common/parse"
+	"context"
+	"flag"
 	"fmt"
-	"github.com/emirpasic/gods/queues/priorityqueue"
-	"github.com/emirpasic/gods/utils"
 	"github.com/samber/lo"
 	"log"
 	"slices"
-	"strings"
 )
 
 type Card struct {
-	Index          int
-	WinningNumbers []int
-	Numbers        []int
+	Index          int   `parse:"id"`
+	WinningNumbers []int `parse:"winning"`
+	Numbers        []int `parse:"have"`
 }
 
-type CardWithCount struct {
-	Card
-	Count int
-}
+const PrintCopies = false
 
 func (c Card) WorthPoints() int {
-	total := 0
-	for _, num := range c.Numbers {
-		if lo.Contains(c.WinningNumbers, num) {
-			if total == 0 {
-				total = 1
-			} else {
-				total *= 2
-			}
-		}
+	matches := c.MatchingNumbers()
+	if matches == 0 {
+		return 0
 	}
-	return total
+	return 1 << (matches - 1)
 }
 
 func (c Card) MatchingNumbers() int {
+	winning := make(map[int]struct{}, len(c.WinningNumbers))
+	for _, num := range c.WinningNumbers {
+		winning[num] = struct{}{}
+	}
 	total := 0
 	for _, num := range c.Numbers {
-		if lo.Contains(c.WinningNumbers, num) {
+		if _, ok := winning[num]; ok {
 			total++
 		}
 	}
 	return total
 }
 
-func CardFromRow(row string) Card {
-	rawCard, rawNumbers, _ := strings.Cut(row, ": ")
-	var cardIndex int
-	count, err := fmt.Sscanf(rawCard, "Card %d", &cardIndex)
-	if err != nil || count == 0 {
-		log.Fatalf("Failed to parse Card index: %s", rawCard)
-	}
-	rawWinningNumbers, rawNumbers, _ := strings.Cut(rawNumbers, "|")
-
-	winningNumbers := common.StringOfNumbersToInts(strings.TrimSpace(rawWinningNumbers))
-	numbers := common.StringOfNumbersToInts(strings.TrimSpace(rawNumbers))
+var (
+	workers = common.WorkersFlag()
+	timeout = common.TimeoutFlag()
+)
 
-	return Card{
-		Index:          cardIndex,
-		WinningNumbers: winningNumbers,
-		Numbers:        numbers,
+func CardFromRow(row string) Card {
+	var card Card
+	if err := parse.Sscan(row, "Card {id}: {winning...} | {have...}", &card); err != nil {
+		log.Fatalf("Failed to parse card: %v", err)
 	}
+	return card
 }
 
 // Part 1
@@ -78,51 +67,34 @@ func CardFromRow(row string) Card {
 //}
 
 func main() {
+	flag.Parse()
+	ctx, cancel := common.SolverContext(*timeout)
+	defer cancel()
+
 	rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t4-scratchcards/1.txt")
 	if err != nil {
-		log.Fatalf("Failed to read file: %w", err)
+		log.Fatalf("Failed to read file: %v", err)
 	}
 	cards := lo.Map(rows, common.NoIndex(CardFromRow))
 
-	//var indexToCard = make(map[int]Card)
-	//for _, card := range cards {
-	//	indexToCard[card.Index] = card
-	//}
-
 	slices.SortFunc(cards, func(a, b Card) int {
 		return a.Index - b.Index
 	})
-	q := priorityqueue.NewWith(func(a, b interface{}) int {
-		cardA := a.(Card)
-		cardB := b.(Card)
-		return utils.IntComparator(cardA.Index, cardB.Index)
+	matches, err := common.ParallelMap(ctx, cards, *workers, func(ctx context.Context, c Card) (int, error) {
+		return c.MatchingNumbers(), nil
 	})
-
-	indexToCardWithCount := make(map[int]*CardWithCount, len(cards))
-	for _, c := range cards {
-		indexToCardWithCount[c.Index] = &(CardWithCount{c, 1})
-		q.Enqueue(c)
+	if err != nil {
+		log.Fatalf("Failed to match numbers: %v", err)
 	}
 
-	processed := 0
-
-	for !q.Empty() {
-		processedCardRaw, _ := q.Dequeue()
-		processedCard := processedCardRaw.(Card)
-		cardWithCount := indexToCardWithCount[processedCard.Index]
-		delete(indexToCardWithCount, processedCard.Index)
-		fmt.Printf("Processed card %d times %d\n", cardWithCount.Card.Index, cardWithCount.Count)
-		processed += cardWithCount.Count
-
-		matchingNumbersCount := processedCard.MatchingNumbers()
-		for i := 1; i <= matchingNumbersCount; i++ {
-			newIndex := processedCard.Index + i
-			newCardWithIndex, ok := indexToCardWithCount[newIndex]
-			if ok {
-				newCardWithIndex.Count += cardWithCount.Count
-			}
+	cascade, err := RunCascade(cards, matches)
+	if err != nil {
+		log.Fatalf("Failed to win copies: %v", err)
+	}
+	if PrintCopies {
+		for _, c := range cascade.Cards {
+			fmt.Printf("Card %d: %d matches, %d copies\n", c.Index, c.Matches, c.Copies)
 		}
 	}
-	fmt.Printf("%d\n", processed)
-
+	fmt.Printf("%d\n", cascade.Total)
 }
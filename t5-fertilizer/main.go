@@ -2,6 +2,8 @@ package main
 
 import (
 	"advent_of_code/common"
+	"context"
+	"flag"
 	"fmt"
 	"github.com/emirpasic/gods/maps/treemap"
 	"github.com/emirpasic/gods/utils"
@@ -149,10 +151,14 @@ func searchRanges(ranges []Range, m *treemap.Map) []Range {
 	return mergedRanges
 }
 
-func pipelineSearchRanges(ranges []Range, maps ...*treemap.Map) []Range {
-	return lo.Reduce(maps, func(ranges []Range, m *treemap.Map, index int) []Range {
-		return searchRanges(ranges, m)
-	}, ranges)
+func pipelineSearchRanges(ctx context.Context, ranges []Range, maps ...*treemap.Map) ([]Range, error) {
+	for i, m := range maps {
+		if err := common.CheckInterrupted(ctx, "%d of %d maps", i, len(maps)); err != nil {
+			return nil, err
+		}
+		ranges = searchRanges(ranges, m)
+	}
+	return ranges, nil
 }
 
 func pipelineSearch(value int, maps ...*treemap.Map) int {
@@ -220,17 +226,23 @@ func pipelineSearch(value int, maps ...*treemap.Map) int {
 
 // Part 2
 
+var timeout = common.TimeoutFlag()
+
 func main() {
+	flag.Parse()
+	ctx, cancel := common.SolverContext(*timeout)
+	defer cancel()
+
 	const path = "/Users/iv/Code/advent-of-code-2023/t5-fertilizer/1.txt"
 	file, err := os.Open(path)
 	if err != nil {
-		log.Fatalf("Failed to open file %s", file)
+		log.Fatalf("Failed to open file %s: %v", path, err)
 	}
 	defer file.Close()
 
 	rawContents, err := io.ReadAll(file)
 	if err != nil {
-		log.Fatalf("Failed to read file %s", file)
+		log.Fatalf("Failed to read file %s: %v", path, err)
 	}
 	contents := string(rawContents)
 	components := strings.Split(contents, "\n\n")
@@ -243,6 +255,9 @@ func main() {
 	temperatureToHumidity := parseRangeMap(components[6], "temperature-to-humidity")
 	humidityToLocation := parseRangeMap(components[7], "humidity-to-location")
 
-	locations := pipelineSearchRanges(seeds, seedToSoil, soilToFertilizer, fertilizerToWater, waterToLight, lightToTemperature, temperatureToHumidity, humidityToLocation)
+	locations, err := pipelineSearchRanges(ctx, seeds, seedToSoil, soilToFertilizer, fertilizerToWater, waterToLight, lightToTemperature, temperatureToHumidity, humidityToLocation)
+	if err != nil {
+		log.Fatalf("Failed to map the seeds: %v", err)
+	}
 	fmt.Printf("Location: %+v", locations[0].Value)
 }
@@ -2,12 +2,18 @@ package main
 
 import (
 	"advent_of_code/common"
+	"advent_of_code/common/numtheory"
+	"advent_of_code/common/parse"
 	"fmt"
 	"github.com/samber/lo"
 	"log"
+	"strconv"
 	"strings"
 )
 
+// Part 1 reads the numbers as they are, part 2 joins their digits.
+const JoinDigits = true
+
 type LapRecord struct {
 	Time     int64
 	Distance int64
@@ -18,50 +24,126 @@ type Run struct {
 	RunFor  int64
 }
 
-func (r LapRecord) RecordBeats() []Run {
-	runs := make([]Run, 0)
-	for wait := int64(1); wait <= r.Time-1; wait++ {
-		run := r.Time - wait
-		if run*wait > r.Distance {
-			runs = append(runs, Run{wait, run})
-		}
+func (r LapRecord) beats(wait int64) bool {
+	return wait*(r.Time-wait) > r.Distance
+}
+
+// RecordBeatRange returns the shortest and the longest wait that beat the
+// record, every wait in between beats it too. The waits solve
+// wait^2 - Time*wait + Distance < 0, the roots are only estimated with the
+// integer square root of the discriminant and then corrected exactly.
+func (r LapRecord) RecordBeatRange() (int64, int64, bool, error) {
+	if r.Time < 0 || r.Distance < 0 {
+		return 0, 0, false, fmt.Errorf("negative record %+v", r)
+	}
+	timeSquared, err := numtheory.MulChecked(r.Time, r.Time)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	// No wait goes further than Time^2/4, this also keeps 4*Distance in range.
+	if r.Distance > timeSquared/4 {
+		return 0, 0, false, nil
+	}
+	discriminant := timeSquared - 4*r.Distance
+	// Distances are symmetric around Time/2, where they are the largest.
+	middle := r.Time / 2
+	root, err := numtheory.Isqrt(discriminant)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	shortest := (r.Time - root) / 2
+	for shortest > 0 && r.beats(shortest-1) {
+		shortest--
+	}
+	for shortest <= middle && !r.beats(shortest) {
+		shortest++
+	}
+	if shortest > middle {
+		return 0, 0, false, nil
+	}
+	return shortest, r.Time - shortest, true, nil
+}
+
+func (r LapRecord) RecordBeats() ([]Run, error) {
+	shortest, longest, ok, err := r.RecordBeatRange()
+	if err != nil || !ok {
+		return nil, err
+	}
+	runs := make([]Run, 0, longest-shortest+1)
+	for wait := shortest; wait <= longest; wait++ {
+		runs = append(runs, Run{wait, r.Time - wait})
 	}
-	return runs
+	return runs, nil
 }
 
-func (r LapRecord) RecordBeatCount() int64 {
-	beats := int64(0)
-	for wait := int64(1); wait <= r.Time-1; wait++ {
-		run := r.Time - wait
-		if run*wait > r.Distance {
-			beats++
+func (r LapRecord) RecordBeatCount() (int64, error) {
+	shortest, longest, ok, err := r.RecordBeatRange()
+	if err != nil || !ok {
+		return 0, err
+	}
+	return longest - shortest + 1, nil
+}
+
+func parseNumbers(line, template string, joinDigits bool) ([]int64, error) {
+	var fields []string
+	if err := parse.Sscan(line, template, &fields); err != nil {
+		return nil, err
+	}
+	if joinDigits {
+		fields = []string{strings.Join(fields, "")}
+	}
+	numbers := make([]int64, 0, len(fields))
+	for _, field := range fields {
+		n, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q: %w", field, err)
 		}
+		numbers = append(numbers, n)
 	}
-	return beats
+	return numbers, nil
 }
 
-func ReadRecords(path string) []LapRecord {
+// ReadRecords reads the times and distances. With joinDigits, the spaces
+// between the numbers are bad kerning, and each line holds a single number.
+func ReadRecords(path string, joinDigits bool) ([]LapRecord, error) {
 	rows, err := common.FileToRows(path)
 	if err != nil {
-		log.Fatalf("Failed to read file: %s", path)
+		return nil, err
 	}
-
-	rawTimes, _ := strings.CutPrefix(rows[0], "Time:")
-	rawDistances, _ := strings.CutPrefix(rows[1], "Distance:")
-	times := common.StringOfNumbersToInts(rawTimes)
-	distances := common.StringOfNumbersToInts(rawDistances)
-	runs := make([]LapRecord, 0, len(times))
+	if len(rows) < 2 {
+		return nil, fmt.Errorf("expected times and distances, got %d lines", len(rows))
+	}
+	times, err := parseNumbers(rows[0], "Time: {times...}", joinDigits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse times: %w", err)
+	}
+	distances, err := parseNumbers(rows[1], "Distance: {distances...}", joinDigits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse distances: %w", err)
+	}
+	if len(times) != len(distances) {
+		return nil, fmt.Errorf("got %d times and %d distances", len(times), len(distances))
+	}
+	records := make([]LapRecord, 0, len(times))
 	for i, t := range times {
-		runs = append(runs, LapRecord{Time: int64(t), Distance: int64(distances[i])})
+		records = append(records, LapRecord{Time: t, Distance: distances[i]})
 	}
-	return runs
+	return records, nil
 }
 
 func main() {
-	runs := ReadRecords("/Users/iv/Code/advent-of-code-2023/t6-wait/2.txt")
-	recordBeats := lo.Map(runs, func(r LapRecord, index int) int64 {
-		return r.RecordBeatCount()
-	})
+	records, err := ReadRecords("/Users/iv/Code/advent-of-code-2023/t6-wait/1.txt", JoinDigits)
+	if err != nil {
+		log.Fatalf("Failed to read records: %v", err)
+	}
+	recordBeats := make([]int64, 0, len(records))
+	for _, r := range records {
+		count, err := r.RecordBeatCount()
+		if err != nil {
+			log.Fatalf("Failed to count the ways to beat %+v: %v", r, err)
+		}
+		recordBeats = append(recordBeats, count)
+	}
 
 	multiplied := lo.Reduce(recordBeats, func(agg int64, r int64, index int) int64 {
 		return agg * r
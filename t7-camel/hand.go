@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"github.com/samber/lo"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	HandSize = 5
+	AllCards = "23456789TJQKA"
+)
+
+type HandType int
+
+const (
+	HighCard HandType = iota
+	OnePair
+	TwoPair
+	ThreeOfAKind
+	FullHouse
+	FourOfAKind
+	FiveOfAKind
+)
+
+func (t HandType) String() string {
+	switch t {
+	case HighCard:
+		return "high card"
+	case OnePair:
+		return "one pair"
+	case TwoPair:
+		return "two pair"
+	case ThreeOfAKind:
+		return "three of a kind"
+	case FullHouse:
+		return "full house"
+	case FourOfAKind:
+		return "four of a kind"
+	case FiveOfAKind:
+		return "five of a kind"
+	}
+	return fmt.Sprintf("HandType(%d)", int(t))
+}
+
+// ClassifyCards looks only at how many times each card repeats.
+func ClassifyCards(cards string) HandType {
+	counts := lo.Values(lo.CountValues([]rune(cards)))
+	slices.SortFunc(counts, func(a, b int) int {
+		return b - a
+	})
+	switch {
+	case counts[0] == 5:
+		return FiveOfAKind
+	case counts[0] == 4:
+		return FourOfAKind
+	case counts[0] == 3 && counts[1] == 2:
+		return FullHouse
+	case counts[0] == 3:
+		return ThreeOfAKind
+	case counts[0] == 2 && counts[1] == 2:
+		return TwoPair
+	case counts[0] == 2:
+		return OnePair
+	}
+	return HighCard
+}
+
+// Ruleset tells how hands are ranked. Order lists the cards from the weakest
+// to the strongest, for ties between hands of the same type. Wild cards act
+// like whatever card makes the strongest type, but keep their own place in
+// Order for ties.
+type Ruleset struct {
+	Name  string
+	Order string
+	Wild  string
+}
+
+var (
+	StandardRules = Ruleset{Name: "standard", Order: AllCards}
+	JokerRules    = Ruleset{Name: "joker", Order: "J23456789TQKA", Wild: "J"}
+)
+
+func (r Ruleset) strength(card rune) int {
+	return strings.IndexRune(r.Order, card)
+}
+
+func (r Ruleset) isWild(card rune) bool {
+	return strings.ContainsRune(r.Wild, card)
+}
+
+// Substitute replaces the wild cards with the card that makes the strongest
+// type. Adding to the most repeated card is always the best, and among the
+// equally repeated ones the strongest is picked. A hand of only wild cards
+// becomes the strongest card of the order.
+func (r Ruleset) Substitute(cards string) string {
+	if r.Wild == "" {
+		return cards
+	}
+	counts := make(map[rune]int)
+	for _, c := range cards {
+		if !r.isWild(c) {
+			counts[c]++
+		}
+	}
+	best := rune(r.Order[len(r.Order)-1])
+	for c, count := range counts {
+		if count > counts[best] || count == counts[best] && r.strength(c) > r.strength(best) {
+			best = c
+		}
+	}
+	return strings.Map(func(c rune) rune {
+		if r.isWild(c) {
+			return best
+		}
+		return c
+	}, cards)
+}
+
+func (r Ruleset) Classify(cards string) HandType {
+	return ClassifyCards(r.Substitute(cards))
+}
+
+// CompareHands orders hands by type first, then card by card. It classifies
+// both hands on every call, Standings classifies every hand once instead.
+func (r Ruleset) CompareHands(a, b Hand) int {
+	return r.compareClassified(a.Cards, r.Classify(a.Cards), b.Cards, r.Classify(b.Cards))
+}
+
+func (r Ruleset) compareClassified(a string, aType HandType, b string, bType HandType) int {
+	if byType := int(aType) - int(bType); byType != 0 {
+		return byType
+	}
+	for i := range a {
+		if byCard := r.strength(rune(a[i])) - r.strength(rune(b[i])); byCard != 0 {
+			return byCard
+		}
+	}
+	return 0
+}
+
+func (r Ruleset) Validate() error {
+	for _, c := range AllCards {
+		if r.strength(c) == -1 {
+			return fmt.Errorf("ruleset %s does not order card %c", r.Name, c)
+		}
+	}
+	if len(r.Order) != len(AllCards) {
+		return fmt.Errorf("ruleset %s orders %d cards instead of %d", r.Name, len(r.Order), len(AllCards))
+	}
+	return nil
+}
+
+type Hand struct {
+	Cards string
+	Bid   int
+}
+
+func ParseHand(rawHand string) (Hand, error) {
+	fields := strings.Fields(rawHand)
+	if len(fields) != 2 {
+		return Hand{}, fmt.Errorf("expected cards and a bid in %q", rawHand)
+	}
+	cards := fields[0]
+	if len(cards) != HandSize {
+		return Hand{}, fmt.Errorf("hand %s has %d cards instead of %d", cards, len(cards), HandSize)
+	}
+	if i := strings.IndexFunc(cards, func(c rune) bool { return !strings.ContainsRune(AllCards, c) }); i != -1 {
+		return Hand{}, fmt.Errorf("unknown card %c in hand %s", cards[i], cards)
+	}
+	bid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return Hand{}, fmt.Errorf("invalid bid in %q: %w", rawHand, err)
+	}
+	return Hand{Cards: cards, Bid: bid}, nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+)
+
+type Standing struct {
+	Hand
+	// Substituted is the hand with the wild cards replaced, the way it was
+	// classified.
+	Substituted string
+	Type        HandType
+	Rank        int
+	Winnings    int64
+}
+
+// Standings ranks the hands from the weakest, rank 1, to the strongest.
+func Standings(hands []Hand, rules Ruleset) []Standing {
+	standings := make([]Standing, 0, len(hands))
+	for _, h := range hands {
+		substituted := rules.Substitute(h.Cards)
+		standings = append(standings, Standing{
+			Hand:        h,
+			Substituted: substituted,
+			Type:        ClassifyCards(substituted),
+		})
+	}
+	slices.SortFunc(standings, func(a, b Standing) int {
+		return rules.compareClassified(a.Cards, a.Type, b.Cards, b.Type)
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+		standings[i].Winnings = int64(i+1) * int64(standings[i].Bid)
+	}
+	return standings
+}
+
+func TotalWinnings(hands []Hand, rules Ruleset) int64 {
+	total := int64(0)
+	for _, s := range Standings(hands, rules) {
+		total += s.Winnings
+	}
+	return total
+}
+
+var reportHeader = []string{"ruleset", "rank", "cards", "played as", "type", "bid", "winnings"}
+
+func (s Standing) record(rules Ruleset) []string {
+	return []string{
+		rules.Name,
+		strconv.Itoa(s.Rank),
+		s.Cards,
+		s.Substituted,
+		s.Type.String(),
+		strconv.Itoa(s.Bid),
+		strconv.FormatInt(s.Winnings, 10),
+	}
+}
+
+func WriteTable(w io.Writer, rules Ruleset, standings []Standing) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	for _, column := range reportHeader {
+		fmt.Fprintf(tw, "%s\t", column)
+	}
+	fmt.Fprintln(tw)
+	total := int64(0)
+	for _, s := range standings {
+		record := s.record(rules)
+		if s.Substituted == s.Cards {
+			// Only show the hands changed by wild cards.
+			record[3] = "-"
+		}
+		for _, value := range record {
+			fmt.Fprintf(tw, "%s\t", value)
+		}
+		fmt.Fprintln(tw)
+		total += s.Winnings
+	}
+	fmt.Fprintf(tw, "%s\t\t\t\t\t\t%d\t\n", rules.Name, total)
+	return tw.Flush()
+}
+
+// WriteCSV writes the header only when asked to, so that the standings of
+// several rulesets can follow each other.
+func WriteCSV(w io.Writer, rules Ruleset, standings []Standing, header bool) error {
+	cw := csv.NewWriter(w)
+	if header {
+		if err := cw.Write(reportHeader); err != nil {
+			return err
+		}
+	}
+	for _, s := range standings {
+		if err := cw.Write(s.record(rules)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"advent_of_code/common/numtheory"
+	"fmt"
+	"github.com/samber/lo"
+	"math/big"
+	"slices"
+)
+
+// GhostSchedule describes all the steps at which a ghost stands on an end
+// node. Steps are absolute, counted from the start node. Before the cycle the
+// ends are a finite list, after cycleStart they repeat with cycleLength period.
+type GhostSchedule struct {
+	preCycleEnds []int64
+	cycleStart   int64
+	cycleLength  int64
+	cycleEnds    []int64
+}
+
+func ScheduleFromCycle(c CycleInfo) GhostSchedule {
+	s := GhostSchedule{
+		cycleStart:  c.firstEncounterSteps,
+		cycleLength: c.secondEncounterSteps - c.firstEncounterSteps,
+	}
+	for _, end := range c.endsEncounteredAt {
+		if end < s.cycleStart {
+			s.preCycleEnds = append(s.preCycleEnds, end)
+		} else {
+			s.cycleEnds = append(s.cycleEnds, end)
+		}
+	}
+	return s
+}
+
+func (s GhostSchedule) IsEndAt(step *big.Int) bool {
+	if step.Cmp(big.NewInt(s.cycleStart)) < 0 {
+		return step.IsInt64() && slices.Contains(s.preCycleEnds, step.Int64())
+	}
+	offset := new(big.Int).Sub(step, big.NewInt(s.cycleStart))
+	offset.Mod(offset, big.NewInt(s.cycleLength))
+	return slices.Contains(s.cycleEnds, s.cycleStart+offset.Int64())
+}
+
+// isSimple checks for the structure the puzzle authors put into the input:
+// no ends before the cycle and a single end hit exactly every cycleLength
+// steps, so the answer is the LCM of the cycle lengths.
+func (s GhostSchedule) isSimple() bool {
+	return len(s.preCycleEnds) == 0 && len(s.cycleEnds) == 1 && s.cycleEnds[0] == s.cycleLength
+}
+
+func FirstCommonEnd(schedules []GhostSchedule) (*big.Int, error) {
+	if len(schedules) == 0 {
+		return nil, fmt.Errorf("no ghosts to walk")
+	}
+	if allSimple(schedules) {
+		return numtheory.LcmAllBig(lo.Map(schedules, func(s GhostSchedule, index int) int64 {
+			return s.cycleLength
+		})), nil
+	}
+
+	// An answer smaller than the latest cycle start is a pre-cycle end of at
+	// least one ghost, so those are checked directly. Standing on the end
+	// before making any step does not count.
+	latestCycleStart := int64(1)
+	var best *big.Int
+	for _, s := range schedules {
+		latestCycleStart = max(latestCycleStart, s.cycleStart)
+		for _, end := range s.preCycleEnds {
+			if end == 0 {
+				continue
+			}
+			step := big.NewInt(end)
+			if (best == nil || step.Cmp(best) < 0) && endsForAll(schedules, step) {
+				best = step
+			}
+		}
+	}
+	if best != nil {
+		return best, nil
+	}
+
+	// Otherwise every ghost is inside its cycle, and each combination of
+	// in-cycle ends is a system of congruences.
+	lowerBound := big.NewInt(latestCycleStart)
+	var solve func(i int, residue, modulus *big.Int)
+	solve = func(i int, residue, modulus *big.Int) {
+		if i == len(schedules) {
+			step := smallestAtLeast(residue, modulus, lowerBound)
+			if best == nil || step.Cmp(best) < 0 {
+				best = step
+			}
+			return
+		}
+		length := big.NewInt(schedules[i].cycleLength)
+		for _, end := range schedules[i].cycleEnds {
+			merged, lcm, err := numtheory.MergeCongruences(residue, modulus, big.NewInt(end), length)
+			if err == nil {
+				solve(i+1, merged, lcm)
+			}
+		}
+	}
+	solve(0, big.NewInt(0), big.NewInt(1))
+	if best == nil {
+		return nil, fmt.Errorf("ghosts never stand on end nodes at the same time: %w", numtheory.ErrNoSolution)
+	}
+	return best, nil
+}
+
+func allSimple(schedules []GhostSchedule) bool {
+	for _, s := range schedules {
+		if !s.isSimple() {
+			return false
+		}
+	}
+	return true
+}
+
+func endsForAll(schedules []GhostSchedule, step *big.Int) bool {
+	for _, s := range schedules {
+		if !s.IsEndAt(step) {
+			return false
+		}
+	}
+	return true
+}
+
+func smallestAtLeast(residue, modulus, lowerBound *big.Int) *big.Int {
+	if residue.Cmp(lowerBound) >= 0 {
+		return new(big.Int).Set(residue)
+	}
+	// residue + k*modulus >= lowerBound with the smallest k.
+	k := new(big.Int).Sub(lowerBound, residue)
+	k.Add(k, modulus)
+	k.Sub(k, big.NewInt(1))
+	k.Div(k, modulus)
+	return k.Mul(k, modulus).Add(k, residue)
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"advent_of_code/common/numtheory"
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/samber/lo"
+	"io"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+var ErrUnreachable = errors.New("end is unreachable")
+
+type Network struct {
+	Instructions []byte
+	nameToEdge   map[string]*Edge
+}
+
+func (n *Network) ensureEdgeExists(name string) *Edge {
+	edge, ok := n.nameToEdge[name]
+	if !ok {
+		edge = &Edge{name: name}
+		n.nameToEdge[name] = edge
+	}
+	return edge
+}
+
+// EdgeFromString parses a node definition like "AAA = (BBB, CCC)".
+func (n *Network) EdgeFromString(s string) (*Edge, error) {
+	name, children, found := strings.Cut(s, "=")
+	if !found {
+		return nil, fmt.Errorf("missing '=' in %q", s)
+	}
+	children = strings.TrimSpace(children)
+	if !strings.HasPrefix(children, "(") || !strings.HasSuffix(children, ")") {
+		return nil, fmt.Errorf("children of %q are not in parentheses", s)
+	}
+	left, right, found := strings.Cut(strings.Trim(children, "()"), ",")
+	if !found {
+		return nil, fmt.Errorf("missing ',' between children in %q", s)
+	}
+	name, left, right = strings.TrimSpace(name), strings.TrimSpace(left), strings.TrimSpace(right)
+	if name == "" || left == "" || right == "" {
+		return nil, fmt.Errorf("empty node name in %q", s)
+	}
+
+	edge := n.ensureEdgeExists(name)
+	if edge.left != nil {
+		return nil, fmt.Errorf("node %s is defined twice", name)
+	}
+	edge.left = n.ensureEdgeExists(left)
+	edge.right = n.ensureEdgeExists(right)
+	return edge, nil
+}
+
+func ParseNetwork(r io.Reader) (*Network, error) {
+	n := &Network{nameToEdge: make(map[string]*Edge)}
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if lineNumber == 1 {
+			for i, c := range line {
+				if c != 'L' && c != 'R' {
+					return nil, fmt.Errorf("line 1, position %d: invalid instruction %q", i+1, c)
+				}
+			}
+			n.Instructions = []byte(line)
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		if _, err := n.EdgeFromString(line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the network: %w", err)
+	}
+	if len(n.Instructions) == 0 {
+		return nil, fmt.Errorf("no instructions")
+	}
+	for name, edge := range n.nameToEdge {
+		if edge.left == nil {
+			return nil, fmt.Errorf("node %s is referenced but never defined", name)
+		}
+	}
+	return n, nil
+}
+
+// Starts lists the names of all nodes matching the predicate, sorted.
+func (n *Network) Starts(isStart func(string) bool) []string {
+	starts := lo.Filter(lo.Keys(n.nameToEdge), func(name string, index int) bool {
+		return isStart(name)
+	})
+	slices.Sort(starts)
+	return starts
+}
+
+func (n *Network) edge(name string) (*Edge, error) {
+	e, ok := n.nameToEdge[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown node %s", name)
+	}
+	return e, nil
+}
+
+// Walk follows the instructions from start and returns the number of steps
+// until an end node is reached. Once the walker is back in a state (node and
+// instruction position) it has already been in, it is going in circles, and
+// ErrUnreachable is returned.
+func (n *Network) Walk(start string, instructions []byte, isEnd func(string) bool) (int64, error) {
+	e, err := n.edge(start)
+	if err != nil {
+		return 0, err
+	}
+	if len(instructions) == 0 {
+		return 0, fmt.Errorf("no instructions")
+	}
+	visited := make(map[EdgeStep]struct{})
+	commandPointer := int64(0)
+	totalSteps := int64(0)
+	for {
+		es := EdgeStep{e, commandPointer}
+		if _, already := visited[es]; already {
+			return 0, fmt.Errorf("walking from %s: %w", start, ErrUnreachable)
+		}
+		visited[es] = struct{}{}
+
+		switch instructions[commandPointer] {
+		case 'L':
+			e = e.left
+		case 'R':
+			e = e.right
+		default:
+			return 0, fmt.Errorf("invalid instruction %c", instructions[commandPointer])
+		}
+		commandPointer = (commandPointer + 1) % int64(len(instructions))
+		totalSteps++
+		if isEnd(e.name) {
+			return totalSteps, nil
+		}
+	}
+}
+
+// WalkAll moves a walker from every start simultaneously and returns the
+// number of steps until all of them stand on end nodes at the same time.
+func (n *Network) WalkAll(starts []string, instructions []byte, isEnd func(string) bool) (*big.Int, error) {
+	if len(instructions) == 0 {
+		return nil, fmt.Errorf("no instructions")
+	}
+	if slices.ContainsFunc(instructions, func(c byte) bool { return c != 'L' && c != 'R' }) {
+		return nil, fmt.Errorf("invalid instructions %s", instructions)
+	}
+	schedules := make([]GhostSchedule, 0, len(starts))
+	for _, start := range starts {
+		e, err := n.edge(start)
+		if err != nil {
+			return nil, err
+		}
+		schedule := ScheduleFromCycle(findCycle(e, instructions, isEnd))
+		if len(schedule.preCycleEnds) == 0 && len(schedule.cycleEnds) == 0 {
+			return nil, fmt.Errorf("walking from %s: %w", start, ErrUnreachable)
+		}
+		schedules = append(schedules, schedule)
+	}
+	steps, err := FirstCommonEnd(schedules)
+	if errors.Is(err, numtheory.ErrNoSolution) {
+		return nil, fmt.Errorf("%w: %w", ErrUnreachable, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("finding the common end: %w", err)
+	}
+	return steps, nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"advent_of_code/common/numtheory"
+	"advent_of_code/common/parse"
+	"errors"
+	"fmt"
+)
+
+var ErrNotPolynomial = errors.New("differences never reach all zeros")
+
+// History keeps the forward differences of the values, the k-th of them
+// being the first value of the k-th difference row. The sequence is the
+// polynomial of the given degree going through the values.
+type History struct {
+	Values      []int64
+	differences []int64
+	degree      int
+}
+
+func NewHistory(values []int64) (History, error) {
+	if len(values) == 0 {
+		return History{}, fmt.Errorf("empty history")
+	}
+	h := History{Values: values}
+	row := values
+	for !AllZeros(row) {
+		// The last value of a row differences to nothing, so there must be a
+		// row of zeros before it to trust the extrapolation.
+		if len(row) == 1 {
+			return History{}, fmt.Errorf("%d values, degree at least %d: %w", len(values), len(values)-1, ErrNotPolynomial)
+		}
+		h.differences = append(h.differences, row[0])
+		next, err := Differentiate(row)
+		if err != nil {
+			return History{}, err
+		}
+		row = next
+	}
+	h.degree = len(h.differences) - 1
+	return h, nil
+}
+
+func HistoryFromString(s string) (History, error) {
+	var values []int64
+	if err := parse.Sscan(s, "{values...}", &values); err != nil {
+		return History{}, err
+	}
+	return NewHistory(values)
+}
+
+func AllZeros(values []int64) bool {
+	for _, v := range values {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func Differentiate(values []int64) ([]int64, error) {
+	diff := make([]int64, 0, len(values)-1)
+	for i := 0; i < len(values)-1; i++ {
+		d, err := numtheory.SubChecked(values[i+1], values[i])
+		if err != nil {
+			return nil, err
+		}
+		diff = append(diff, d)
+	}
+	return diff, nil
+}
+
+// Degree is -1 for a history of zeros.
+func (h History) Degree() int {
+	return h.degree
+}
+
+// At evaluates the sequence at position x, the first value being at 0, with
+// the Newton forward difference formula: the sum of C(x, k) times the k-th
+// difference. Positions before the first value are negative.
+func (h History) At(x int64) (int64, error) {
+	var value int64
+	binomial := int64(1)
+	for k, difference := range h.differences {
+		if k > 0 {
+			factor, err := numtheory.SubChecked(x, int64(k-1))
+			if err != nil {
+				return 0, err
+			}
+			// C(x, k) = C(x, k-1) * (x-k+1) / k is an integer. Once the common
+			// part of C(x, k-1) and k is divided out, the rest of k divides
+			// x-k+1, so nothing is multiplied beyond C(x, k) itself.
+			g, err := numtheory.Gcd(int64(k), binomial%int64(k))
+			if err != nil {
+				return 0, err
+			}
+			if binomial, err = numtheory.MulChecked(binomial/g, factor/(int64(k)/g)); err != nil {
+				return 0, err
+			}
+		}
+		term, err := numtheory.MulChecked(binomial, difference)
+		if err != nil {
+			return 0, err
+		}
+		if value, err = numtheory.AddChecked(value, term); err != nil {
+			return 0, err
+		}
+	}
+	return value, nil
+}
+
+// ExtrapolateRight is the value steps positions after the last one.
+func (h History) ExtrapolateRight(steps int) (int64, error) {
+	x, err := numtheory.AddChecked(int64(len(h.Values)-1), int64(steps))
+	if err != nil {
+		return 0, err
+	}
+	return h.At(x)
+}
+
+// ExtrapolateLeft is the value steps positions before the first one.
+func (h History) ExtrapolateLeft(steps int) (int64, error) {
+	x, err := numtheory.SubChecked(0, int64(steps))
+	if err != nil {
+		return 0, err
+	}
+	return h.At(x)
+}
@@ -2,89 +2,69 @@ package main
 
 import (
 	"advent_of_code/common"
+	"context"
+	"flag"
 	"fmt"
 	"github.com/samber/lo"
 	"log"
 )
 
-func AllZeros(values []int) bool {
-	return lo.EveryBy(values, func(item int) bool {
-		return item == 0
-	})
-}
-
-func Differentiate(values []int) []int {
-	diff := make([]int, 0, len(values)-1)
-	for i := 0; i < len(values)-1; i++ {
-		diff = append(diff, values[i+1]-values[i])
-	}
-	return diff
-}
-
-func (h History) ExtrapolateRight() int {
-	diffs := [][]int{h.values}
-	for !AllZeros(diffs[len(diffs)-1]) {
-		diffs = append(diffs, Differentiate(diffs[len(diffs)-1]))
-	}
+var (
+	workers = common.WorkersFlag()
+	timeout = common.TimeoutFlag()
+)
 
-	extrapolatingIndex := len(diffs) - 1
-	extrapolatedValue := 0
-	for extrapolatingIndex > 0 {
-		newExtrapolatedValue := extrapolatedValue + diffs[extrapolatingIndex-1][len(diffs[extrapolatingIndex-1])-1]
-		extrapolatedValue = newExtrapolatedValue
-		extrapolatingIndex--
-	}
-	return extrapolatedValue
-}
+// Steps is how far the histories are extrapolated.
+const Steps = 1
 
-func (h History) ExtrapolateLeft() int {
-	diffs := [][]int{h.values}
-	for !AllZeros(diffs[len(diffs)-1]) {
-		diffs = append(diffs, Differentiate(diffs[len(diffs)-1]))
-	}
-
-	extrapolatingIndex := len(diffs) - 1
-	extrapolatedValue := 0
-	for extrapolatingIndex > 0 {
-		newExtrapolatedValue := diffs[extrapolatingIndex-1][0] - extrapolatedValue
-		extrapolatedValue = newExtrapolatedValue
-		extrapolatingIndex--
+func ReadHistories(path string) ([]History, error) {
+	rows, err := common.FileToRows(path)
+	if err != nil {
+		return nil, err
 	}
-	return extrapolatedValue
-}
-
-type History struct {
-	values []int
-}
-
-func HistoryFromString(s string) History {
-	return History{
-		common.StringOfNumbersToInts(s),
+	histories := make([]History, 0, len(rows))
+	for i, row := range rows {
+		h, err := HistoryFromString(row)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		histories = append(histories, h)
 	}
+	return histories, nil
 }
 
 // Part 1
 //func main() {
-//	rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t9-migrate/1.txt")
+//	histories, err := ReadHistories("/Users/iv/Code/advent-of-code-2023/t9-migrate/1.txt")
 //	if err != nil {
-//		log.Fatalf("Failed to open file %w", err)
+//		log.Fatalf("Failed to read histories: %v", err)
+//	}
+//	var sum int64
+//	for _, h := range histories {
+//		value, err := h.ExtrapolateRight(Steps)
+//		if err != nil {
+//			log.Fatalf("Failed to extrapolate %v: %v", h.Values, err)
+//		}
+//		sum += value
 //	}
-//	histories := lo.Map(rows, common.NoIndex(HistoryFromString))
-//	extrapolated := lo.Map(histories, func(h History, index int) int {
-//		return h.ExtrapolateRight()
-//	})
-//	fmt.Printf("Sum: %d\n", lo.Sum(extrapolated))
+//	fmt.Printf("Sum: %d\n", sum)
 //}
 
 // Part 2
 func main() {
-	rows, err := common.FileToRows("/Users/iv/Code/advent-of-code-2023/t9-migrate/1.txt")
+	flag.Parse()
+	ctx, cancel := common.SolverContext(*timeout)
+	defer cancel()
+
+	histories, err := ReadHistories("/Users/iv/Code/advent-of-code-2023/t9-migrate/1.txt")
 	if err != nil {
-		log.Fatalf("Failed to open file %w", err)
+		log.Fatalf("Failed to read histories: %v", err)
 	}
-	histories := lo.Map(rows, common.NoIndex(HistoryFromString))
-	extrapolated := lo.Map(histories, func(h History, index int) int {
-		return h.ExtrapolateLeft()
+	extrapolated, err := common.ParallelMap(ctx, histories, *workers, func(ctx context.Context, h History) (int64, error) {
+		return h.ExtrapolateLeft(Steps)
 	})
+	if err != nil {
+		log.Fatalf("Failed to extrapolate: %v", err)
+	}
 	fmt.Printf("Sum: %d\n", lo.Sum(extrapolated))
 }